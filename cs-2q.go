@@ -0,0 +1,113 @@
+package table
+
+import (
+	"container/list"
+	"errors"
+
+	"github.com/named-data/ndnd/fw/defn"
+)
+
+// Cs2Q is a 2Q replacement policy for the Content Store.
+// New entries enter the FIFO A1in. Entries evicted from A1in are remembered
+// in the ghost queue A1out, and an entry that is inserted again while in A1out
+// is promoted to the LRU queue Am. Entries referenced only once thus never
+// displace the entries in Am.
+type Cs2Q struct {
+	cs        PitCsTable
+	inPercent float64
+	a1in      *list.List // of uint64, oldest first
+	am        *list.List // of uint64, least recently used first
+	locations map[uint64]*list.Element
+	inAm      map[uint64]bool
+	a1out     *csGhost[struct{}]
+}
+
+func init() {
+	RegisterCsPolicy("2q", []CsPolicyParam{
+		{Name: "in_percent", Default: 25.0},
+		{Name: "out_percent", Default: 50.0},
+	}, func(params CsPolicyParams) error {
+		return errors.Join(
+			checkCsPolicyRange("in_percent", params.Float("in_percent"), 0.0, 100.0),
+			checkCsPolicyMin("out_percent", params.Float("out_percent"), 0.0),
+		)
+	}, func(cs PitCsTable, params CsPolicyParams) (CsReplacementPolicy, error) {
+		return NewCs2Q(cs, params.Float("in_percent"), params.Float("out_percent")), nil
+	})
+}
+
+// NewCs2Q creates a new 2Q replacement policy for the Content Store.
+// inPercent is the share of the capacity reserved for A1in, and outPercent
+// is the size of A1out relative to the capacity.
+func NewCs2Q(cs PitCsTable, inPercent float64, outPercent float64) *Cs2Q {
+	q := new(Cs2Q)
+	q.cs = cs
+	q.inPercent = inPercent
+	q.a1in = list.New()
+	q.am = list.New()
+	q.locations = make(map[uint64]*list.Element)
+	q.inAm = make(map[uint64]bool)
+	q.a1out = newCsGhost[struct{}](int(float64(csCapacity())*outPercent/100.0), CsGhostLRU)
+	return q
+}
+
+// AfterInsert is called after a new entry is inserted into the Content Store.
+func (q *Cs2Q) AfterInsert(index uint64, wire []byte, data *defn.FwData) {
+	if q.a1out.contains(index) {
+		q.a1out.remove(index)
+		q.locations[index] = q.am.PushBack(index)
+		q.inAm[index] = true
+	} else {
+		q.locations[index] = q.a1in.PushBack(index)
+	}
+}
+
+// AfterRefresh is called after a new data packet refreshes an existing entry in the Content Store.
+func (q *Cs2Q) AfterRefresh(index uint64, wire []byte, data *defn.FwData) {
+	q.use(index)
+}
+
+// BeforeErase is called before an entry is erased from the Content Store through management.
+func (q *Cs2Q) BeforeErase(index uint64, wire []byte) {
+	if location, ok := q.locations[index]; ok {
+		if q.inAm[index] {
+			q.am.Remove(location)
+		} else {
+			q.a1in.Remove(location)
+		}
+		delete(q.locations, index)
+		delete(q.inAm, index)
+	}
+}
+
+// BeforeUse is called before an entry in the Content Store is used to satisfy a pending Interest.
+func (q *Cs2Q) BeforeUse(index uint64, wire []byte) {
+	q.use(index)
+}
+
+// EvictEntries is called to instruct the policy to evict enough entries to reduce
+// the Content Store size below its size limit.
+func (q *Cs2Q) EvictEntries() {
+	kin := int(float64(csCapacity()) * q.inPercent / 100.0)
+	for csOverCapacity(q.cs, len(q.locations)) {
+		var indexToErase uint64
+		if q.a1in.Len() > 0 && (q.a1in.Len() > kin || q.am.Len() == 0) {
+			indexToErase = q.a1in.Remove(q.a1in.Front()).(uint64)
+			q.a1out.put(indexToErase, struct{}{})
+		} else {
+			indexToErase = q.am.Remove(q.am.Front()).(uint64)
+			delete(q.inAm, indexToErase)
+		}
+		delete(q.locations, indexToErase)
+		q.cs.eraseCsDataFromReplacementStrategy(indexToErase)
+	}
+}
+
+// use moves an entry of Am to its most recently used end. Entries in A1in
+// keep their position, since references shortly after insertion are
+// considered correlated.
+func (q *Cs2Q) use(index uint64) {
+	if q.inAm[index] {
+		q.am.MoveToBack(q.locations[index])
+	}
+}
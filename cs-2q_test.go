@@ -0,0 +1,36 @@
+package table
+
+import (
+	"slices"
+	"testing"
+)
+
+// TestCs2QAdmitsGhostHitsToAm checks that an entry inserted again while in A1out
+// goes to Am, where a scan of entries referenced once does not evict it.
+func TestCs2QAdmitsGhostHitsToAm(t *testing.T) {
+	setTestCsCapacity(t, 4)
+	cs := &testCsTable{}
+	q := NewCs2Q(cs, 25.0, 50.0)
+
+	for index := range uint64(5) {
+		q.AfterInsert(index, nil, nil)
+	}
+	q.EvictEntries()
+	if !slices.Equal(cs.erased, []uint64{0}) || !q.a1out.contains(0) {
+		t.Fatalf("evicted %v, want 0 into A1out", cs.erased)
+	}
+
+	q.AfterInsert(0, nil, nil)
+	if !q.inAm[0] || q.a1out.contains(0) {
+		t.Fatalf("entry found in A1out was not admitted to Am")
+	}
+	q.EvictEntries()
+
+	for index := uint64(10); index < 20; index++ {
+		q.AfterInsert(index, nil, nil)
+		q.EvictEntries()
+	}
+	if slices.Contains(cs.erased[1:], 0) || !q.inAm[0] {
+		t.Errorf("scan evicted %v, want 0 to stay in Am", cs.erased[1:])
+	}
+}
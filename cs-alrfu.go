@@ -0,0 +1,177 @@
+package table
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/named-data/ndnd/fw/core"
+	"github.com/named-data/ndnd/fw/defn"
+)
+
+// CsALRFU is an adaptive LRFU replacement policy that tunes lambda online.
+// Two shadow caches replay the same references with a lambda one step
+// towards recency and one step towards frequency. They only keep metadata,
+// so they act as ghost lists. Every interval references, the live lambda
+// moves one step towards the shadow that would have had more hits.
+//
+// Steps are taken in ln λ, that is lambda is multiplied or divided by e^step,
+// since the behavior of LRFU changes as much between 0.001 and 0.01 as between
+// 0.1 and 1. Lambda is kept in [alrfuLambdaMin, 1]. If both shadows have as
+// many hits, lambda keeps moving in the same direction, turning back at the
+// bounds, so that it does not stay in a region where lambda makes no
+// difference.
+type CsALRFU struct {
+	*CsLRFU
+	makeWeight func(lambda float64) WeightFunction
+	lambda     float64
+	step       float64
+	interval   uint
+	direction  float64 // +1 towards recency, -1 towards frequency
+
+	recency       *CsLRFU // lambda · e^step
+	frequency     *CsLRFU // lambda / e^step
+	recencyHits   uint
+	frequencyHits uint
+	references    uint
+}
+
+func init() {
+	params := append([]CsPolicyParam{}, lrfuParams...)
+	params = append(params,
+		CsPolicyParam{Name: "step", Default: 0.5},
+		CsPolicyParam{Name: "interval", Default: 1000},
+	)
+	RegisterCsPolicy("alrfu", params, func(params CsPolicyParams) error {
+		var err error
+		if step := params.Float("step"); !(step > 0.0) {
+			err = fmt.Errorf("parameter %q: %v is not positive", "step", step)
+		}
+		return errors.Join(
+			validateLrfuParams(params),
+			err,
+			checkCsPolicyMin("interval", float64(params.Int("interval")), 1),
+		)
+	}, func(cs PitCsTable, params CsPolicyParams) (CsReplacementPolicy, error) {
+		return NewCsALRFU(cs, lrfuWeightMaker(params), params.Float("lambda"),
+			params.Float("step"), params.Int("interval"), lrfuTimeUnit(params)), nil
+	})
+}
+
+// alrfuLambdaMin is the smallest lambda of CsALRFU, as 0 cannot be reached by
+// geometric steps.
+const alrfuLambdaMin = 1e-4
+
+// NewCsALRFU creates a new adaptive LRFU replacement policy starting at the given lambda.
+// Each step multiplies or divides lambda by e^step.
+func NewCsALRFU(
+	cs PitCsTable,
+	makeWeight func(lambda float64) WeightFunction,
+	lambda float64,
+	step float64,
+	interval int,
+	timeUnit time.Duration,
+) *CsALRFU {
+	if interval < 1 {
+		interval = 1
+	}
+	a := &CsALRFU{
+		makeWeight: makeWeight,
+		step:       step,
+		interval:   uint(interval),
+		direction:  -1,
+	}
+	a.lambda = a.shift(lambda, 0)
+	a.CsLRFU = NewCsLRFU(cs, makeWeight(a.lambda), timeUnit)
+	a.recency = NewCsLRFU(nil, makeWeight(a.shift(a.lambda, 1)), timeUnit)
+	a.frequency = NewCsLRFU(nil, makeWeight(a.shift(a.lambda, -1)), timeUnit)
+	return a
+}
+
+// shift returns lambda moved by the given number of steps in ln λ, clamped
+// to [alrfuLambdaMin, 1].
+func (a *CsALRFU) shift(lambda float64, steps float64) float64 {
+	return math.Min(math.Max(lambda*math.Exp(steps*a.step), alrfuLambdaMin), 1.0)
+}
+
+// Lambda returns the current live lambda.
+func (a *CsALRFU) Lambda() float64 {
+	return a.lambda
+}
+
+// Stats returns the current lambda, the hits of each shadow cache in this
+// interval, and the evictions that scanned all entries.
+func (a *CsALRFU) Stats() map[string]float64 {
+	return map[string]float64{
+		"lambda":         a.lambda,
+		"recency_hits":   float64(a.recencyHits),
+		"frequency_hits": float64(a.frequencyHits),
+		"full_scans":     float64(a.fullScans),
+	}
+}
+
+// shadowAccess replays a reference on a shadow cache and reports whether it was a hit.
+func shadowAccess(shadow *CsLRFU, index uint64) bool {
+	if shadow.contains(index) {
+		shadow.reference(index)
+		return true
+	}
+	shadow.AfterInsert(index, nil, nil)
+	shadow.EvictEntries()
+	return false
+}
+
+// observe replays a reference on both shadow caches and adapts lambda at the end of each interval.
+func (a *CsALRFU) observe(index uint64) {
+	if shadowAccess(a.recency, index) {
+		a.recencyHits++
+	}
+	if shadowAccess(a.frequency, index) {
+		a.frequencyHits++
+	}
+
+	a.references++
+	if a.references < a.interval {
+		return
+	}
+
+	switch {
+	case a.recencyHits > a.frequencyHits:
+		a.direction = 1
+	case a.frequencyHits > a.recencyHits:
+		a.direction = -1
+	case a.shift(a.lambda, a.direction) == a.lambda:
+		// tie at a bound: turn back
+		a.direction = -a.direction
+	}
+	if lambda := a.shift(a.lambda, a.direction); lambda != a.lambda {
+		core.Log.Debug(nil, "Adapted ALRFU lambda", "from", a.lambda, "to", lambda,
+			"recency_hits", a.recencyHits, "frequency_hits", a.frequencyHits)
+		a.lambda = lambda
+		a.CsLRFU.setWeight(a.makeWeight(lambda))
+		a.recency.setWeight(a.makeWeight(a.shift(lambda, 1)))
+		a.frequency.setWeight(a.makeWeight(a.shift(lambda, -1)))
+	}
+	a.references = 0
+	a.recencyHits = 0
+	a.frequencyHits = 0
+}
+
+// AfterInsert is called after a new entry is inserted into the Content Store.
+func (a *CsALRFU) AfterInsert(index uint64, wire []byte, data *defn.FwData) {
+	a.observe(index)
+	a.CsLRFU.AfterInsert(index, wire, data)
+}
+
+// AfterRefresh is called after a new data packet refreshes an existing entry in the Content Store.
+func (a *CsALRFU) AfterRefresh(index uint64, wire []byte, data *defn.FwData) {
+	a.observe(index)
+	a.CsLRFU.AfterRefresh(index, wire, data)
+}
+
+// BeforeUse is called before an entry in the Content Store is used to satisfy a pending Interest.
+func (a *CsALRFU) BeforeUse(index uint64, wire []byte) {
+	a.observe(index)
+	a.CsLRFU.BeforeUse(index, wire)
+}
@@ -0,0 +1,89 @@
+package table
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// TestCsALRFUConvergesToFrequency runs CsALRFU from lambda 1 (LRU) on a trace
+// where a small set of popular entries is interleaved with entries referenced
+// only once, on which LFU does better than LRU. Hits are either uses or
+// refreshes, which CsALRFU must both replay on its shadow caches.
+func TestCsALRFUConvergesToFrequency(t *testing.T) {
+	hits := []struct {
+		name string
+		hit  func(a *CsALRFU, index uint64)
+	}{
+		{"use", func(a *CsALRFU, index uint64) { a.BeforeUse(index, nil) }},
+		{"refresh", func(a *CsALRFU, index uint64) { a.AfterRefresh(index, nil, nil) }},
+	}
+	for _, tt := range hits {
+		t.Run(tt.name, func(t *testing.T) {
+			setTestCsCapacity(t, 100)
+
+			a := NewCsALRFU(&testCsTable{}, WeightH1, 1.0, 0.5, 500, 0)
+			rng := rand.New(rand.NewSource(1))
+			zipf := rand.NewZipf(rng, 1.2, 1, 79)
+			next := uint64(1000) // entries referenced once
+			for range 100000 {
+				index := next
+				if rng.Intn(2) == 0 {
+					index = zipf.Uint64()
+				} else {
+					next++
+				}
+				if a.contains(index) {
+					tt.hit(a, index)
+				} else {
+					a.AfterInsert(index, nil, nil)
+					a.EvictEntries()
+				}
+			}
+			if a.Lambda() > 0.01 {
+				t.Errorf("lambda is %v, want it to converge towards 0", a.Lambda())
+			}
+		})
+	}
+}
+
+// TestCsALRFUConvergesToRecency runs CsALRFU from the smallest lambda (LFU) on
+// a trace whose popular entries change in phases, on which LRU does better
+// than LFU.
+func TestCsALRFUConvergesToRecency(t *testing.T) {
+	setTestCsCapacity(t, 100)
+
+	a := NewCsALRFU(&testCsTable{}, WeightH1, 0.0, 0.5, 500, 0)
+	rng := rand.New(rand.NewSource(1))
+	for i := range 100000 {
+		index := uint64(i/2000*80 + rng.Intn(80)) // 80 entries per phase
+		if a.contains(index) {
+			a.BeforeUse(index, nil)
+		} else {
+			a.AfterInsert(index, nil, nil)
+			a.EvictEntries()
+		}
+	}
+	if a.Lambda() < 0.01 {
+		t.Errorf("lambda is %v, want it to move away from 0", a.Lambda())
+	}
+}
+
+// TestCsALRFUObservesRefreshes checks that refreshes count as hits of the
+// shadow caches, like uses.
+func TestCsALRFUObservesRefreshes(t *testing.T) {
+	setTestCsCapacity(t, 100)
+
+	a := NewCsALRFU(&testCsTable{}, WeightH1, 0.5, 0.5, 1000, 0)
+	for index := range uint64(10) {
+		a.AfterInsert(index, nil, nil)
+	}
+	for range 5 {
+		for index := range uint64(10) {
+			a.AfterRefresh(index, nil, nil)
+		}
+	}
+	stats := a.Stats()
+	if stats["recency_hits"] != 50 || stats["frequency_hits"] != 50 {
+		t.Errorf("shadow hits are %v and %v, want 50", stats["recency_hits"], stats["frequency_hits"])
+	}
+}
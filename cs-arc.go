@@ -0,0 +1,170 @@
+package table
+
+import (
+	"container/list"
+	"math"
+
+	"github.com/named-data/ndnd/fw/defn"
+)
+
+// CsARC is an Adaptive Replacement Cache (ARC) replacement policy.
+// Resident entries are split between T1, holding entries seen once recently,
+// and T2, holding entries seen at least twice. The ghost lists B1 and B2 remember
+// entries recently evicted from T1 and T2. A miss that hits a ghost list moves
+// the target size p of T1 towards the list that would have kept the entry.
+type CsARC struct {
+	cs       PitCsTable
+	t1       *list.List // of uint64, least recently used first
+	t2       *list.List // of uint64, least recently used first
+	resident map[uint64]*arcEntry
+	b1       *csGhost[struct{}]
+	b2       *csGhost[struct{}]
+	p        float64 // target size of T1
+
+	// lastInB2 is set if the last inserted entry was found in B2, in which case
+	// a T1 of exactly size p gives up its entry on the next replacement.
+	lastInB2 bool
+}
+
+type arcEntry struct {
+	elem *list.Element
+	inT2 bool
+}
+
+func init() {
+	RegisterCsPolicy("arc", nil, nil, func(cs PitCsTable, _ CsPolicyParams) (CsReplacementPolicy, error) {
+		return NewCsARC(cs), nil
+	})
+}
+
+// NewCsARC creates a new ARC replacement policy for the Content Store.
+func NewCsARC(cs PitCsTable) *CsARC {
+	a := new(CsARC)
+	a.cs = cs
+	a.t1 = list.New()
+	a.t2 = list.New()
+	a.resident = make(map[uint64]*arcEntry)
+	a.b1 = newCsGhost[struct{}](csCapacity(), CsGhostLRU)
+	a.b2 = newCsGhost[struct{}](csCapacity(), CsGhostLRU)
+	return a
+}
+
+// P returns the current target size of T1.
+func (a *CsARC) P() float64 {
+	return a.p
+}
+
+// Stats returns the target size p and the size of each list.
+func (a *CsARC) Stats() map[string]float64 {
+	return map[string]float64{
+		"p":  a.p,
+		"t1": float64(a.t1.Len()),
+		"t2": float64(a.t2.Len()),
+		"b1": float64(a.b1.Len()),
+		"b2": float64(a.b2.Len()),
+	}
+}
+
+// AfterInsert is called after a new entry is inserted into the Content Store.
+func (a *CsARC) AfterInsert(index uint64, wire []byte, data *defn.FwData) {
+	c := float64(csCapacity())
+	a.lastInB2 = false
+
+	switch {
+	case a.b1.contains(index):
+		// Recency would have kept the entry: grow T1
+		a.p = math.Min(c, a.p+math.Max(float64(a.b2.Len())/float64(a.b1.Len()), 1))
+		a.b1.remove(index)
+		a.pushT2(index)
+	case a.b2.contains(index):
+		// Frequency would have kept the entry: shrink T1
+		a.p = math.Max(0, a.p-math.Max(float64(a.b1.Len())/float64(a.b2.Len()), 1))
+		a.b2.remove(index)
+		a.lastInB2 = true
+		a.pushT2(index)
+	default:
+		if a.t1.Len()+a.b1.Len() >= csCapacity() {
+			a.b1.removeOldest()
+		} else if a.t1.Len()+a.t2.Len()+a.b1.Len()+a.b2.Len() >= 2*csCapacity() {
+			a.b2.removeOldest()
+		}
+		a.resident[index] = &arcEntry{elem: a.t1.PushBack(index)}
+	}
+}
+
+// AfterRefresh is called after a new data packet refreshes an existing entry in the Content Store.
+func (a *CsARC) AfterRefresh(index uint64, wire []byte, data *defn.FwData) {
+	a.hit(index)
+}
+
+// BeforeErase is called before an entry is erased from the Content Store through management.
+func (a *CsARC) BeforeErase(index uint64, wire []byte) {
+	a.removeResident(index)
+}
+
+// BeforeUse is called before an entry in the Content Store is used to satisfy a pending Interest.
+func (a *CsARC) BeforeUse(index uint64, wire []byte) {
+	a.hit(index)
+}
+
+// EvictEntries is called to instruct the policy to evict enough entries to reduce
+// the Content Store size below its size limit.
+func (a *CsARC) EvictEntries() {
+	for csOverCapacity(a.cs, a.t1.Len()+a.t2.Len()) {
+		a.replace()
+	}
+
+	// Keep |T1|+|B1| <= c and |T1|+|T2|+|B1|+|B2| <= 2c
+	for a.t1.Len()+a.b1.Len() > csCapacity() && a.b1.Len() > 0 {
+		a.b1.removeOldest()
+	}
+	for a.t1.Len()+a.t2.Len()+a.b1.Len()+a.b2.Len() > 2*csCapacity() && a.b2.Len() > 0 {
+		a.b2.removeOldest()
+	}
+}
+
+// hit moves a resident entry to the most recently used end of T2.
+func (a *CsARC) hit(index uint64) {
+	if _, ok := a.resident[index]; !ok {
+		return
+	}
+	a.removeResident(index)
+	a.pushT2(index)
+}
+
+// replace evicts the least recently used entry of T1 or T2 into its ghost list.
+func (a *CsARC) replace() {
+	t1Len := a.t1.Len()
+	fromT1 := t1Len > 0 && (float64(t1Len) > a.p || (a.lastInB2 && float64(t1Len) == a.p))
+	if a.t2.Len() == 0 {
+		fromT1 = true
+	}
+
+	var indexToErase uint64
+	if fromT1 {
+		indexToErase = a.t1.Front().Value.(uint64)
+		a.b1.put(indexToErase, struct{}{})
+	} else {
+		indexToErase = a.t2.Front().Value.(uint64)
+		a.b2.put(indexToErase, struct{}{})
+	}
+	a.removeResident(indexToErase)
+	a.cs.eraseCsDataFromReplacementStrategy(indexToErase)
+}
+
+func (a *CsARC) pushT2(index uint64) {
+	a.resident[index] = &arcEntry{elem: a.t2.PushBack(index), inT2: true}
+}
+
+func (a *CsARC) removeResident(index uint64) {
+	entry, ok := a.resident[index]
+	if !ok {
+		return
+	}
+	if entry.inT2 {
+		a.t2.Remove(entry.elem)
+	} else {
+		a.t1.Remove(entry.elem)
+	}
+	delete(a.resident, index)
+}
@@ -0,0 +1,84 @@
+package table
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+// TestCsARCAdaptsToGhostHits checks that the target size of T1 grows on a hit
+// in B1 and shrinks on a hit in B2.
+func TestCsARCAdaptsToGhostHits(t *testing.T) {
+	setTestCsCapacity(t, 4)
+	cs := &testCsTable{}
+	a := NewCsARC(cs)
+
+	for index := range uint64(4) {
+		a.AfterInsert(index, nil, nil)
+	}
+	a.BeforeUse(2, nil)
+	a.BeforeUse(3, nil)
+	a.AfterInsert(4, nil, nil)
+	a.EvictEntries() // T1 is larger than p = 0: 0 goes to B1
+	if !slices.Equal(cs.erased, []uint64{0}) || !a.b1.contains(0) {
+		t.Fatalf("evicted %v, want 0 into B1", cs.erased)
+	}
+
+	a.AfterInsert(0, nil, nil)
+	if a.P() != 1 {
+		t.Errorf("p is %v after a hit in B1, want 1", a.P())
+	}
+	if entry := a.resident[0]; entry == nil || !entry.inT2 {
+		t.Errorf("entry found in B1 is not in T2")
+	}
+	a.EvictEntries() // T1 is larger than p = 1: 1 goes to B1
+
+	a.BeforeUse(4, nil)
+	a.AfterInsert(5, nil, nil)
+	a.EvictEntries() // T1 is not larger than p: 2 goes to B2
+	if !slices.Equal(cs.erased, []uint64{0, 1, 2}) || !a.b2.contains(2) {
+		t.Fatalf("evicted %v, want 0 and 1 into B1, then 2 into B2", cs.erased)
+	}
+
+	a.AfterInsert(2, nil, nil)
+	if a.P() != 0 {
+		t.Errorf("p is %v after a hit in B2, want 0", a.P())
+	}
+}
+
+// TestCsARCOnlyErasesResidentEntries checks on a random trace that only resident
+// entries are erased from the table, so trimming the ghost lists never erases.
+func TestCsARCOnlyErasesResidentEntries(t *testing.T) {
+	const capacity = 20
+	setTestCsCapacity(t, capacity)
+	cs := &testCsTable{}
+	a := NewCsARC(cs)
+
+	rng := rand.New(rand.NewSource(1))
+	for step := range 20000 {
+		index := uint64(rng.Intn(100))
+		if _, ok := a.resident[index]; ok {
+			a.BeforeUse(index, nil)
+			continue
+		}
+		a.AfterInsert(index, nil, nil)
+
+		resident := make(map[uint64]bool, len(a.resident))
+		for index := range a.resident {
+			resident[index] = true
+		}
+		cs.erased = cs.erased[:0]
+		a.EvictEntries()
+		for _, index := range cs.erased {
+			if !resident[index] {
+				t.Fatalf("step %d: erased %d, which is not resident", step, index)
+			}
+		}
+		if len(resident)-len(cs.erased) != min(len(resident), capacity) {
+			t.Fatalf("step %d: erased %d of %d entries", step, len(cs.erased), len(resident))
+		}
+		if a.b1.Len()+a.b2.Len() > capacity {
+			t.Fatalf("step %d: ghost lists hold %d entries", step, a.b1.Len()+a.b2.Len())
+		}
+	}
+}
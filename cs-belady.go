@@ -0,0 +1,140 @@
+package table
+
+import (
+	"container/heap"
+	"math"
+	"sort"
+
+	"github.com/named-data/ndnd/fw/defn"
+)
+
+// CsBelady is Belady's optimal (OPT) replacement policy for the Content Store.
+// It is given the whole sequence of future references in advance and evicts
+// the entry whose next reference is the farthest in the future, or that is
+// never referenced again. It cannot run in a forwarder, but replaying a trace
+// with it gives the upper bound of the hit ratio for that trace and capacity.
+//
+// Each insertion, refresh and use counts as the next reference of the
+// sequence. An index that does not match the sequence at that point is still
+// handled: its next reference is looked up from the current position.
+type CsBelady struct {
+	cs        PitCsTable
+	now       int // position of the current reference in the sequence
+	positions map[uint64][]int
+	queue     beladyHeap
+	entries   map[uint64]*beladyEntry
+}
+
+type beladyEntry struct {
+	index   uint64
+	nextUse int // math.MaxInt if never referenced again
+	pos     int
+}
+
+// beladyHeap orders entries by their next reference, farthest first.
+type beladyHeap []*beladyEntry
+
+func (h beladyHeap) Len() int { return len(h) }
+func (h beladyHeap) Less(i, j int) bool {
+	if h[i].nextUse != h[j].nextUse {
+		return h[i].nextUse > h[j].nextUse
+	}
+	return h[i].index < h[j].index
+}
+func (h beladyHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].pos = i
+	h[j].pos = j
+}
+func (h *beladyHeap) Push(x interface{}) {
+	entry := x.(*beladyEntry)
+	entry.pos = len(*h)
+	*h = append(*h, entry)
+}
+func (h *beladyHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	entry := old[n-1]
+	entry.pos = -1
+	*h = old[:n-1]
+	return entry
+}
+
+// NewCsBelady creates a new Belady replacement policy for the Content Store
+// that knows the given sequence of future references, in order.
+// It is not registered by name, since the sequence cannot come from the
+// configuration; trace replay tools create it directly, and install it in a
+// PitCsTree with NewPitCSWithPolicy.
+func NewCsBelady(cs PitCsTable, future []uint64) *CsBelady {
+	b := new(CsBelady)
+	b.cs = cs
+	b.positions = make(map[uint64][]int)
+	for i, index := range future {
+		b.positions[index] = append(b.positions[index], i)
+	}
+	b.entries = make(map[uint64]*beladyEntry)
+	return b
+}
+
+// AfterInsert is called after a new entry is inserted into the Content Store.
+func (b *CsBelady) AfterInsert(index uint64, wire []byte, data *defn.FwData) {
+	if _, ok := b.entries[index]; ok {
+		b.reference(index)
+		return
+	}
+	entry := &beladyEntry{index: index, nextUse: b.nextUse(index)}
+	b.now++
+	b.entries[index] = entry
+	heap.Push(&b.queue, entry)
+}
+
+// AfterRefresh is called after a new data packet refreshes an existing entry in the Content Store.
+func (b *CsBelady) AfterRefresh(index uint64, wire []byte, data *defn.FwData) {
+	b.reference(index)
+}
+
+// BeforeErase is called before an entry is erased from the Content Store through management.
+func (b *CsBelady) BeforeErase(index uint64, wire []byte) {
+	if entry, ok := b.entries[index]; ok {
+		heap.Remove(&b.queue, entry.pos)
+		delete(b.entries, index)
+	}
+}
+
+// BeforeUse is called before an entry in the Content Store is used to satisfy a pending Interest.
+func (b *CsBelady) BeforeUse(index uint64, wire []byte) {
+	b.reference(index)
+}
+
+// EvictEntries is called to instruct the policy to evict enough entries to reduce
+// the Content Store size below its size limit.
+func (b *CsBelady) EvictEntries() {
+	for csOverCapacity(b.cs, len(b.entries)) {
+		entry := heap.Pop(&b.queue).(*beladyEntry)
+		delete(b.entries, entry.index)
+		b.cs.eraseCsDataFromReplacementStrategy(entry.index)
+	}
+}
+
+// reference consumes the current reference of the sequence for a resident entry.
+func (b *CsBelady) reference(index uint64) {
+	entry, ok := b.entries[index]
+	if !ok {
+		b.now++
+		return
+	}
+	entry.nextUse = b.nextUse(index)
+	b.now++
+	heap.Fix(&b.queue, entry.pos)
+}
+
+// nextUse returns the position of the first reference to the index after the
+// current one, or math.MaxInt if there is none.
+func (b *CsBelady) nextUse(index uint64) int {
+	positions := b.positions[index]
+	i := sort.SearchInts(positions, b.now+1)
+	if i == len(positions) {
+		return math.MaxInt
+	}
+	return positions[i]
+}
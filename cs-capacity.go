@@ -0,0 +1,53 @@
+package table
+
+// csCapacityBytes is the byte limit of the Content Store, or 0 for no limit.
+var csCapacityBytes int
+
+// SetCfgCsCapacityBytes sets the maximum number of bytes held in the Content Store.
+// A value of 0 or less disables the byte limit, leaving only the entry limit.
+// The configuration loader is expected to call it before NewPitCS, like
+// SetCfgCsPolicyParams; until then there is no byte limit.
+func SetCfgCsCapacityBytes(bytes int) {
+	csCapacityBytes = max(bytes, 0)
+}
+
+// CfgCsCapacityBytes returns the maximum number of bytes held in the Content Store,
+// or 0 if there is no byte limit.
+func CfgCsCapacityBytes() int {
+	return csCapacityBytes
+}
+
+// csEntryLimit overrides CfgCsCapacity if positive. Tests and benchmarks set it,
+// since they run without a forwarder configuration.
+var csEntryLimit int
+
+// csCapacity returns the maximum number of entries held in the Content Store.
+// Policies use it instead of calling CfgCsCapacity directly.
+func csCapacity() int {
+	if csEntryLimit > 0 {
+		return csEntryLimit
+	}
+	return CfgCsCapacity()
+}
+
+// CsByteCounter is implemented by tables that track the number of bytes
+// stored in their Content Store.
+type CsByteCounter interface {
+	// CsBytes returns the total size of the Data wires held in the Content Store.
+	CsBytes() int
+}
+
+// csOverCapacity returns whether a Content Store holding the given number of
+// entries must evict. This is the case if it is over the entry limit, or if
+// the table counts bytes and is over the byte limit. Policies loop on it in
+// EvictEntries, so it is false once the Content Store is empty.
+func csOverCapacity(cs PitCsTable, entries int) bool {
+	if entries > csCapacity() {
+		return true
+	}
+	if entries == 0 || CfgCsCapacityBytes() == 0 {
+		return false
+	}
+	counter, ok := cs.(CsByteCounter)
+	return ok && counter.CsBytes() > CfgCsCapacityBytes()
+}
@@ -0,0 +1,100 @@
+package table
+
+import (
+	"container/ring"
+	"sync/atomic"
+
+	"github.com/named-data/ndnd/fw/defn"
+)
+
+// CsClock is a CLOCK replacement policy for the Content Store.
+// Entries sit on a circular list swept by a hand. A hit only sets the entry's
+// reference bit, so using an entry never reorders any structure; the hand
+// clears reference bits as it passes and evicts the first entry without one.
+//
+// The reference bit is atomic so that setting it would not need a lock, but
+// BeforeUse still reads the entries map, which is not safe for concurrent use.
+// Like every policy, CsClock is only called by the forwarding thread that owns
+// its PitCsTree.
+type CsClock struct {
+	cs      PitCsTable
+	hand    *ring.Ring // of *clockEntry, next entry to examine
+	entries map[uint64]*ring.Ring
+}
+
+type clockEntry struct {
+	index uint64
+	ref   atomic.Bool
+}
+
+func init() {
+	RegisterCsPolicy("clock", nil, nil, func(cs PitCsTable, _ CsPolicyParams) (CsReplacementPolicy, error) {
+		return NewCsClock(cs), nil
+	})
+}
+
+// NewCsClock creates a new CLOCK replacement policy for the Content Store.
+func NewCsClock(cs PitCsTable) *CsClock {
+	c := new(CsClock)
+	c.cs = cs
+	c.entries = make(map[uint64]*ring.Ring)
+	return c
+}
+
+// AfterInsert is called after a new entry is inserted into the Content Store.
+// The entry is placed just behind the hand, so it is the last to be examined.
+func (c *CsClock) AfterInsert(index uint64, wire []byte, data *defn.FwData) {
+	r := ring.New(1)
+	r.Value = &clockEntry{index: index}
+	if c.hand == nil {
+		c.hand = r
+	} else {
+		c.hand.Prev().Link(r)
+	}
+	c.entries[index] = r
+}
+
+// AfterRefresh is called after a new data packet refreshes an existing entry in the Content Store.
+func (c *CsClock) AfterRefresh(index uint64, wire []byte, data *defn.FwData) {
+	c.BeforeUse(index, wire)
+}
+
+// BeforeErase is called before an entry is erased from the Content Store through management.
+func (c *CsClock) BeforeErase(index uint64, wire []byte) {
+	if r, ok := c.entries[index]; ok {
+		c.remove(r)
+	}
+}
+
+// BeforeUse is called before an entry in the Content Store is used to satisfy a pending Interest.
+func (c *CsClock) BeforeUse(index uint64, wire []byte) {
+	if r, ok := c.entries[index]; ok {
+		r.Value.(*clockEntry).ref.Store(true)
+	}
+}
+
+// EvictEntries is called to instruct the policy to evict enough entries to reduce
+// the Content Store size below its size limit.
+func (c *CsClock) EvictEntries() {
+	for csOverCapacity(c.cs, len(c.entries)) {
+		entry := c.hand.Value.(*clockEntry)
+		if entry.ref.Swap(false) {
+			c.hand = c.hand.Next()
+			continue
+		}
+		c.remove(c.hand)
+		c.cs.eraseCsDataFromReplacementStrategy(entry.index)
+	}
+}
+
+func (c *CsClock) remove(r *ring.Ring) {
+	delete(c.entries, r.Value.(*clockEntry).index)
+	if r == c.hand {
+		c.hand = r.Next()
+		if c.hand == r {
+			c.hand = nil
+			return
+		}
+	}
+	r.Prev().Unlink(1)
+}
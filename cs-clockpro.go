@@ -0,0 +1,252 @@
+package table
+
+import (
+	"container/ring"
+	"sync/atomic"
+
+	"github.com/named-data/ndnd/fw/defn"
+)
+
+// CsClockPro is a CLOCK-Pro replacement policy for the Content Store.
+// Resident entries are hot or cold. A cold entry is in a test period after it
+// is inserted or referenced, and an evicted cold entry stays on the clock as
+// a non-resident entry until its test period ends. A cold entry referenced
+// again during its test period becomes hot, and the target number of cold
+// entries adapts to how often this happens for non-resident entries.
+//
+// The cold hand evicts or promotes cold entries, the hot hand demotes
+// unreferenced hot entries, and the test hand ends test periods when too many
+// non-resident entries are kept. As with CsClock, a hit only sets the entry's
+// reference bit and all reorganisation happens in EvictEntries, and it is
+// likewise only called by the forwarding thread that owns its PitCsTree.
+type CsClockPro struct {
+	cs       PitCsTable
+	entries  map[uint64]*ring.Ring // of *clockProEntry
+	handHot  *ring.Ring
+	handCold *ring.Ring
+	handTest *ring.Ring
+
+	countHot   int
+	countCold  int
+	countTest  int // non-resident entries
+	coldTarget int // adaptive target for the number of resident cold entries
+}
+
+type clockProKind int
+
+const (
+	clockProNonResident clockProKind = iota
+	clockProCold
+	clockProHot
+)
+
+type clockProEntry struct {
+	index  uint64
+	ref    atomic.Bool
+	kind   clockProKind
+	inTest bool
+}
+
+func init() {
+	RegisterCsPolicy("clock-pro", nil, nil, func(cs PitCsTable, _ CsPolicyParams) (CsReplacementPolicy, error) {
+		return NewCsClockPro(cs), nil
+	})
+}
+
+// NewCsClockPro creates a new CLOCK-Pro replacement policy for the Content Store.
+func NewCsClockPro(cs PitCsTable) *CsClockPro {
+	p := new(CsClockPro)
+	p.cs = cs
+	p.entries = make(map[uint64]*ring.Ring)
+	p.coldTarget = csCapacity()
+	return p
+}
+
+// Stats returns the adaptive cold target and the number of entries of each kind.
+func (p *CsClockPro) Stats() map[string]float64 {
+	return map[string]float64{
+		"cold_target":  float64(p.coldTarget),
+		"hot":          float64(p.countHot),
+		"cold":         float64(p.countCold),
+		"non_resident": float64(p.countTest),
+	}
+}
+
+// AfterInsert is called after a new entry is inserted into the Content Store.
+func (p *CsClockPro) AfterInsert(index uint64, wire []byte, data *defn.FwData) {
+	if r, ok := p.entries[index]; ok {
+		entry := r.Value.(*clockProEntry)
+		if entry.kind != clockProNonResident {
+			entry.ref.Store(true)
+			return
+		}
+
+		// Faulted during its test period: more cold entries would have kept it
+		if p.coldTarget < csCapacity() {
+			p.coldTarget++
+		}
+		p.unlink(r)
+		p.countTest--
+		entry.kind = clockProHot
+		entry.inTest = false
+		entry.ref.Store(false)
+		p.link(r)
+		p.countHot++
+		return
+	}
+
+	r := ring.New(1)
+	r.Value = &clockProEntry{index: index, kind: clockProCold, inTest: true}
+	p.link(r)
+	p.countCold++
+}
+
+// AfterRefresh is called after a new data packet refreshes an existing entry in the Content Store.
+func (p *CsClockPro) AfterRefresh(index uint64, wire []byte, data *defn.FwData) {
+	p.BeforeUse(index, wire)
+}
+
+// BeforeErase is called before an entry is erased from the Content Store through management.
+func (p *CsClockPro) BeforeErase(index uint64, wire []byte) {
+	r, ok := p.entries[index]
+	if !ok {
+		return
+	}
+	switch r.Value.(*clockProEntry).kind {
+	case clockProHot:
+		p.countHot--
+	case clockProCold:
+		p.countCold--
+	default:
+		return
+	}
+	p.unlink(r)
+}
+
+// BeforeUse is called before an entry in the Content Store is used to satisfy a pending Interest.
+func (p *CsClockPro) BeforeUse(index uint64, wire []byte) {
+	if r, ok := p.entries[index]; ok {
+		if entry := r.Value.(*clockProEntry); entry.kind != clockProNonResident {
+			entry.ref.Store(true)
+		}
+	}
+}
+
+// EvictEntries is called to instruct the policy to evict enough entries to reduce
+// the Content Store size below its size limit.
+func (p *CsClockPro) EvictEntries() {
+	for csOverCapacity(p.cs, p.countHot+p.countCold) {
+		if p.countHot > csCapacity()-p.coldTarget || p.countCold == 0 {
+			p.runHandHot()
+		} else {
+			p.runHandCold()
+		}
+	}
+	for p.countTest > csCapacity() {
+		p.runHandTest()
+	}
+}
+
+// link puts an entry on the clock just behind the cold hand, so that the
+// cold hand reaches it last.
+func (p *CsClockPro) link(r *ring.Ring) {
+	entry := r.Value.(*clockProEntry)
+	p.entries[entry.index] = r
+	if p.handCold == nil {
+		p.handHot, p.handCold, p.handTest = r, r, r
+		return
+	}
+	p.handCold.Prev().Link(r)
+}
+
+// unlink takes an entry off the clock and forgets it, moving any hand on it forward.
+func (p *CsClockPro) unlink(r *ring.Ring) {
+	delete(p.entries, r.Value.(*clockProEntry).index)
+	if r.Next() == r {
+		p.handHot, p.handCold, p.handTest = nil, nil, nil
+		return
+	}
+	if r == p.handHot {
+		p.handHot = r.Next()
+	}
+	if r == p.handCold {
+		p.handCold = r.Next()
+	}
+	if r == p.handTest {
+		p.handTest = r.Next()
+	}
+	r.Prev().Unlink(1)
+}
+
+// endTest ends the test period of the entry under a hand. A non-resident entry
+// is forgotten, and the cold target shrinks since it was never referenced again.
+func (p *CsClockPro) endTest(r *ring.Ring) {
+	entry := r.Value.(*clockProEntry)
+	if entry.kind == clockProNonResident {
+		p.unlink(r)
+		p.countTest--
+		if p.coldTarget > 1 {
+			p.coldTarget--
+		}
+	} else {
+		entry.inTest = false
+	}
+}
+
+// runHandCold examines the entry under the cold hand. A referenced cold entry
+// becomes hot if it is in its test period, or starts a new one otherwise;
+// an unreferenced one is evicted.
+func (p *CsClockPro) runHandCold() {
+	r := p.handCold
+	p.handCold = r.Next()
+	entry := r.Value.(*clockProEntry)
+	if entry.kind != clockProCold {
+		return
+	}
+
+	if entry.ref.Swap(false) {
+		if entry.inTest {
+			entry.kind = clockProHot
+			entry.inTest = false
+			p.countCold--
+			p.countHot++
+		} else {
+			entry.inTest = true
+		}
+		return
+	}
+
+	p.countCold--
+	if entry.inTest {
+		entry.kind = clockProNonResident
+		p.countTest++
+	} else {
+		p.unlink(r)
+	}
+	p.cs.eraseCsDataFromReplacementStrategy(entry.index)
+}
+
+// runHandHot examines the entry under the hot hand, demoting a hot entry that
+// was not referenced since the last pass and ending the test period of cold ones.
+func (p *CsClockPro) runHandHot() {
+	r := p.handHot
+	p.handHot = r.Next()
+	entry := r.Value.(*clockProEntry)
+	switch {
+	case entry.kind != clockProHot:
+		p.endTest(r)
+	case !entry.ref.Swap(false):
+		entry.kind = clockProCold
+		p.countHot--
+		p.countCold++
+	}
+}
+
+// runHandTest ends the test period of the entry under the test hand.
+func (p *CsClockPro) runHandTest() {
+	r := p.handTest
+	p.handTest = r.Next()
+	if r.Value.(*clockProEntry).kind != clockProHot {
+		p.endTest(r)
+	}
+}
@@ -0,0 +1,112 @@
+package table
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// checkClockProInvariants checks the counts of CsClockPro against the entries on
+// its clock and the bounds on them.
+func checkClockProInvariants(t *testing.T, p *CsClockPro, step int) {
+	t.Helper()
+	counts := make(map[clockProKind]int)
+	size := 0
+	if p.handCold != nil {
+		p.handCold.Do(func(value interface{}) {
+			entry := value.(*clockProEntry)
+			counts[entry.kind]++
+			size++
+			if p.entries[entry.index] == nil {
+				t.Fatalf("step %d: entry %d is on the clock but not in the map", step, entry.index)
+			}
+			if entry.kind == clockProHot && entry.inTest {
+				t.Fatalf("step %d: hot entry %d is in a test period", step, entry.index)
+			}
+			if entry.kind == clockProNonResident && !entry.inTest {
+				t.Fatalf("step %d: non-resident entry %d is not in a test period", step, entry.index)
+			}
+		})
+	}
+	switch {
+	case size != len(p.entries):
+		t.Fatalf("step %d: %d entries on the clock, %d in the map", step, size, len(p.entries))
+	case counts[clockProHot] != p.countHot || counts[clockProCold] != p.countCold || counts[clockProNonResident] != p.countTest:
+		t.Fatalf("step %d: counted %v, policy has hot %d, cold %d, non-resident %d",
+			step, counts, p.countHot, p.countCold, p.countTest)
+	case p.countHot+p.countCold > csCapacity() || p.countTest > csCapacity():
+		t.Fatalf("step %d: %d resident and %d non-resident entries", step, p.countHot+p.countCold, p.countTest)
+	case p.coldTarget < 1 || p.coldTarget > csCapacity():
+		t.Fatalf("step %d: cold target %d", step, p.coldTarget)
+	}
+}
+
+// TestCsClockProAdaptsColdTarget checks that the cold target shrinks when test
+// periods of non-resident entries end, and grows when a non-resident entry is
+// inserted again during its test period.
+func TestCsClockProAdaptsColdTarget(t *testing.T) {
+	const capacity = 5
+	setTestCsCapacity(t, capacity)
+	p := NewCsClockPro(&testCsTable{})
+
+	for index := range uint64(30) {
+		p.AfterInsert(index, nil, nil)
+		p.EvictEntries()
+		checkClockProInvariants(t, p, int(index))
+	}
+	shrunk := p.coldTarget
+	if shrunk >= capacity {
+		t.Fatalf("cold target is %d after a scan, want it below %d", shrunk, capacity)
+	}
+
+	var test uint64
+	found := false
+	for index, r := range p.entries {
+		if r.Value.(*clockProEntry).kind == clockProNonResident {
+			test, found = index, true
+			break
+		}
+	}
+	if !found {
+		t.Fatalf("no non-resident entry after a scan")
+	}
+	p.AfterInsert(test, nil, nil)
+	if p.coldTarget != shrunk+1 {
+		t.Errorf("cold target is %d after a hit on a non-resident entry, want %d", p.coldTarget, shrunk+1)
+	}
+	if p.entries[test].Value.(*clockProEntry).kind != clockProHot {
+		t.Errorf("non-resident entry inserted again is not hot")
+	}
+	p.EvictEntries()
+	checkClockProInvariants(t, p, 30)
+}
+
+// TestCsClockProInvariants checks the invariants of CsClockPro on a random trace,
+// and that only resident entries are erased from the table.
+func TestCsClockProInvariants(t *testing.T) {
+	setTestCsCapacity(t, 20)
+	cs := &testCsTable{}
+	p := NewCsClockPro(cs)
+
+	rng := rand.New(rand.NewSource(1))
+	for step := range 20000 {
+		index := uint64(rng.ExpFloat64() * 30)
+		if r, ok := p.entries[index]; ok && r.Value.(*clockProEntry).kind != clockProNonResident {
+			p.BeforeUse(index, nil)
+			continue
+		}
+		p.AfterInsert(index, nil, nil)
+
+		resident := make(map[uint64]bool)
+		for index, r := range p.entries {
+			resident[index] = r.Value.(*clockProEntry).kind != clockProNonResident
+		}
+		cs.erased = cs.erased[:0]
+		p.EvictEntries()
+		for _, index := range cs.erased {
+			if !resident[index] {
+				t.Fatalf("step %d: erased %d, which is not resident", step, index)
+			}
+		}
+		checkClockProInvariants(t, p, step)
+	}
+}
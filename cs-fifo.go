@@ -0,0 +1,64 @@
+package table
+
+import (
+	"container/list"
+
+	"github.com/named-data/ndnd/fw/defn"
+)
+
+// CsFIFO is a first in, first out (FIFO) replacement policy for the Content Store.
+// Entries are evicted in insertion order regardless of how often they are used.
+type CsFIFO struct {
+	cs        PitCsTable
+	queue     *list.List
+	locations map[uint64]*list.Element
+}
+
+func init() {
+	RegisterCsPolicy("fifo", nil, nil, func(cs PitCsTable, _ CsPolicyParams) (CsReplacementPolicy, error) {
+		return NewCsFIFO(cs), nil
+	})
+}
+
+// NewCsFIFO creates a new FIFO replacement policy for the Content Store.
+func NewCsFIFO(cs PitCsTable) *CsFIFO {
+	f := new(CsFIFO)
+	f.cs = cs
+	f.queue = list.New()
+	f.locations = make(map[uint64]*list.Element)
+	return f
+}
+
+// AfterInsert is called after a new entry is inserted into the Content Store.
+func (f *CsFIFO) AfterInsert(index uint64, wire []byte, data *defn.FwData) {
+	f.locations[index] = f.queue.PushBack(index)
+}
+
+// AfterRefresh is called after a new data packet refreshes an existing entry in the Content Store.
+// A refresh does not change the position of the entry.
+func (f *CsFIFO) AfterRefresh(index uint64, wire []byte, data *defn.FwData) {
+}
+
+// BeforeErase is called before an entry is erased from the Content Store through management.
+func (f *CsFIFO) BeforeErase(index uint64, wire []byte) {
+	if location, ok := f.locations[index]; ok {
+		f.queue.Remove(location)
+		delete(f.locations, index)
+	}
+}
+
+// BeforeUse is called before an entry in the Content Store is used to satisfy a pending Interest.
+// Use does not change the position of the entry.
+func (f *CsFIFO) BeforeUse(index uint64, wire []byte) {
+}
+
+// EvictEntries is called to instruct the policy to evict enough entries to reduce
+// the Content Store size below its size limit.
+func (f *CsFIFO) EvictEntries() {
+	for csOverCapacity(f.cs, f.queue.Len()) {
+		indexToErase := f.queue.Front().Value.(uint64)
+		f.cs.eraseCsDataFromReplacementStrategy(indexToErase)
+		f.queue.Remove(f.queue.Front())
+		delete(f.locations, indexToErase)
+	}
+}
@@ -0,0 +1,168 @@
+package table
+
+import (
+	"container/heap"
+
+	"github.com/named-data/ndnd/fw/defn"
+)
+
+// GDSF cost models, selected by the cost parameter.
+const (
+	// GdsfCostUniform gives every entry a cost of 1, favouring small popular
+	// entries to maximise the object hit ratio.
+	GdsfCostUniform = "uniform"
+	// GdsfCostSize gives every entry a cost equal to its size, so that size
+	// cancels out and the policy maximises the byte hit ratio like LFU-DA.
+	GdsfCostSize = "size"
+)
+
+// CsGDSF is a GreedyDual-Size-Frequency replacement policy for the Content Store.
+// Each entry has the priority L + freq*cost/size, where size is the length of
+// its wire encoding, freq the number of times it was inserted or used while
+// resident, and L an inflation value raised to the priority of every evicted
+// entry. The entry with the lowest priority is evicted, so large entries must
+// be used more often than small ones to stay in the Content Store.
+type CsGDSF struct {
+	cs        PitCsTable
+	cost      string
+	inflation float64 // L
+	seq       uint64
+	queue     gdsfHeap
+	entries   map[uint64]*gdsfEntry
+}
+
+type gdsfEntry struct {
+	index    uint64
+	freq     float64
+	size     float64
+	priority float64
+	seq      uint64 // breaks ties, oldest first
+	pos      int
+}
+
+// gdsfHeap orders entries by priority, lowest first.
+type gdsfHeap []*gdsfEntry
+
+func (h gdsfHeap) Len() int { return len(h) }
+func (h gdsfHeap) Less(i, j int) bool {
+	if h[i].priority != h[j].priority {
+		return h[i].priority < h[j].priority
+	}
+	return h[i].seq < h[j].seq
+}
+func (h gdsfHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].pos = i
+	h[j].pos = j
+}
+func (h *gdsfHeap) Push(x interface{}) {
+	entry := x.(*gdsfEntry)
+	entry.pos = len(*h)
+	*h = append(*h, entry)
+}
+func (h *gdsfHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	entry := old[n-1]
+	entry.pos = -1
+	*h = old[:n-1]
+	return entry
+}
+
+func init() {
+	RegisterCsPolicy("gdsf", []CsPolicyParam{
+		{Name: "cost", Default: GdsfCostUniform, Choices: []string{GdsfCostUniform, GdsfCostSize}},
+	}, nil, func(cs PitCsTable, params CsPolicyParams) (CsReplacementPolicy, error) {
+		return NewCsGDSF(cs, params.String("cost")), nil
+	})
+}
+
+// NewCsGDSF creates a new GDSF replacement policy for the Content Store with the given cost model.
+func NewCsGDSF(cs PitCsTable, cost string) *CsGDSF {
+	g := new(CsGDSF)
+	g.cs = cs
+	g.cost = cost
+	g.entries = make(map[uint64]*gdsfEntry)
+	return g
+}
+
+// Inflation returns the current inflation value L.
+func (g *CsGDSF) Inflation() float64 {
+	return g.inflation
+}
+
+// Stats returns the inflation value.
+func (g *CsGDSF) Stats() map[string]float64 {
+	return map[string]float64{
+		"inflation": g.inflation,
+	}
+}
+
+// AfterInsert is called after a new entry is inserted into the Content Store.
+func (g *CsGDSF) AfterInsert(index uint64, wire []byte, data *defn.FwData) {
+	if _, ok := g.entries[index]; ok {
+		g.use(index, wire)
+		return
+	}
+	g.seq++
+	entry := &gdsfEntry{index: index, freq: 1, size: gdsfSize(wire), seq: g.seq}
+	entry.priority = g.priority(entry)
+	g.entries[index] = entry
+	heap.Push(&g.queue, entry)
+}
+
+// AfterRefresh is called after a new data packet refreshes an existing entry in the Content Store.
+func (g *CsGDSF) AfterRefresh(index uint64, wire []byte, data *defn.FwData) {
+	g.use(index, wire)
+}
+
+// BeforeErase is called before an entry is erased from the Content Store through management.
+func (g *CsGDSF) BeforeErase(index uint64, wire []byte) {
+	if entry, ok := g.entries[index]; ok {
+		heap.Remove(&g.queue, entry.pos)
+		delete(g.entries, index)
+	}
+}
+
+// BeforeUse is called before an entry in the Content Store is used to satisfy a pending Interest.
+func (g *CsGDSF) BeforeUse(index uint64, wire []byte) {
+	g.use(index, nil)
+}
+
+// EvictEntries is called to instruct the policy to evict enough entries to reduce
+// the Content Store size below its size limit.
+func (g *CsGDSF) EvictEntries() {
+	for csOverCapacity(g.cs, len(g.entries)) {
+		entry := heap.Pop(&g.queue).(*gdsfEntry)
+		g.inflation = entry.priority
+		delete(g.entries, entry.index)
+		g.cs.eraseCsDataFromReplacementStrategy(entry.index)
+	}
+}
+
+// use increments the frequency of a resident entry and recomputes its priority
+// with the current inflation value. A non-nil wire updates the entry's size.
+func (g *CsGDSF) use(index uint64, wire []byte) {
+	entry, ok := g.entries[index]
+	if !ok {
+		return
+	}
+	if wire != nil {
+		entry.size = gdsfSize(wire)
+	}
+	entry.freq++
+	entry.priority = g.priority(entry)
+	heap.Fix(&g.queue, entry.pos)
+}
+
+func (g *CsGDSF) priority(entry *gdsfEntry) float64 {
+	if g.cost == GdsfCostSize {
+		return g.inflation + entry.freq
+	}
+	return g.inflation + entry.freq/entry.size
+}
+
+// gdsfSize returns the size of an entry, at least 1 byte.
+func gdsfSize(wire []byte) float64 {
+	return float64(max(len(wire), 1))
+}
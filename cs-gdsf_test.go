@@ -0,0 +1,42 @@
+package table
+
+import (
+	"slices"
+	"testing"
+)
+
+// TestCsGDSFKeepsSmallFrequentEntries checks that with uniform costs, large
+// entries are evicted before small entries used as often, and that a stream of
+// one-time entries raises the inflation value without evicting the small ones.
+func TestCsGDSFKeepsSmallFrequentEntries(t *testing.T) {
+	setTestCsCapacity(t, 10)
+	cs := &testCsTable{}
+	g := NewCsGDSF(cs, GdsfCostUniform)
+
+	for index := range uint64(10) {
+		size := 100 // 0 to 4 are small
+		if index >= 5 {
+			size = 8000
+		}
+		g.AfterInsert(index, make([]byte, size), nil)
+		for range 3 {
+			g.BeforeUse(index, nil)
+		}
+	}
+
+	for index := uint64(100); index < 120; index++ {
+		g.AfterInsert(index, make([]byte, 1000), nil)
+		g.EvictEntries()
+	}
+	if got := cs.erased[:5]; !slices.Equal(got, []uint64{5, 6, 7, 8, 9}) {
+		t.Errorf("first evicted %v, want the large entries 5 to 9", got)
+	}
+	for index := range uint64(5) {
+		if _, ok := g.entries[index]; !ok {
+			t.Errorf("small entry %d was evicted", index)
+		}
+	}
+	if g.Inflation() <= 4.0/8000 {
+		t.Errorf("inflation is %v, want it above the priority of the large entries", g.Inflation())
+	}
+}
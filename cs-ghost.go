@@ -0,0 +1,152 @@
+package table
+
+import "container/list"
+
+// Ghost replacement modes, selected by policies that keep a bounded history.
+const (
+	// CsGhostLRU forgets the least recently touched ghost entry.
+	CsGhostLRU = "lru"
+	// CsGhostClock forgets the first ghost entry found without its reference bit by a CLOCK hand.
+	CsGhostClock = "clock"
+)
+
+// csGhost is a bounded history of indices, each carrying a value. It only keeps
+// metadata, so policies use it to remember entries that are no longer resident.
+type csGhost[V any] struct {
+	capacity int
+	clock    bool
+	entries  map[uint64]*csGhostEntry[V]
+
+	order *list.List // LRU mode: of *csGhostEntry, least recently touched first
+
+	ring []*csGhostEntry[V] // CLOCK mode
+	hand int
+}
+
+type csGhostEntry[V any] struct {
+	index uint64
+	value V
+	ref   bool
+	elem  *list.Element
+	slot  int
+}
+
+// newCsGhost creates a ghost history holding at most capacity indices.
+func newCsGhost[V any](capacity int, mode string) *csGhost[V] {
+	if capacity < 1 {
+		capacity = 1
+	}
+	return &csGhost[V]{
+		capacity: capacity,
+		clock:    mode == CsGhostClock,
+		entries:  make(map[uint64]*csGhostEntry[V]),
+		order:    list.New(),
+	}
+}
+
+// Len returns the number of indices in the history.
+func (g *csGhost[V]) Len() int {
+	return len(g.entries)
+}
+
+// contains returns whether the index is in the history without touching it.
+func (g *csGhost[V]) contains(index uint64) bool {
+	_, ok := g.entries[index]
+	return ok
+}
+
+// get returns the value of the index and touches it.
+func (g *csGhost[V]) get(index uint64) (V, bool) {
+	entry, ok := g.entries[index]
+	if !ok {
+		var zero V
+		return zero, false
+	}
+	g.touch(entry)
+	return entry.value, true
+}
+
+// put sets the value of the index and touches it, forgetting another index if the history is full.
+func (g *csGhost[V]) put(index uint64, value V) {
+	if entry, ok := g.entries[index]; ok {
+		entry.value = value
+		g.touch(entry)
+		return
+	}
+
+	entry := &csGhostEntry[V]{index: index, value: value}
+	if g.clock {
+		if len(g.ring) < g.capacity {
+			entry.slot = len(g.ring)
+			g.ring = append(g.ring, entry)
+		} else {
+			entry.slot = g.sweep()
+			delete(g.entries, g.ring[entry.slot].index)
+			g.ring[entry.slot] = entry
+		}
+	} else {
+		if len(g.entries) >= g.capacity {
+			g.removeOldest()
+		}
+		entry.elem = g.order.PushBack(entry)
+	}
+	g.entries[index] = entry
+}
+
+// remove forgets the index.
+func (g *csGhost[V]) remove(index uint64) {
+	entry, ok := g.entries[index]
+	if !ok {
+		return
+	}
+	delete(g.entries, index)
+	if g.clock {
+		// Move the last slot into the hole to keep the ring dense
+		last := g.ring[len(g.ring)-1]
+		g.ring[entry.slot] = last
+		last.slot = entry.slot
+		g.ring = g.ring[:len(g.ring)-1]
+		if g.hand >= len(g.ring) {
+			g.hand = 0
+		}
+	} else {
+		g.order.Remove(entry.elem)
+	}
+}
+
+// removeOldest forgets the index that would be replaced next, returning it.
+func (g *csGhost[V]) removeOldest() (uint64, bool) {
+	if len(g.entries) == 0 {
+		return 0, false
+	}
+	var index uint64
+	if g.clock {
+		index = g.ring[g.sweep()].index
+	} else {
+		index = g.order.Front().Value.(*csGhostEntry[V]).index
+	}
+	g.remove(index)
+	return index, true
+}
+
+func (g *csGhost[V]) touch(entry *csGhostEntry[V]) {
+	if g.clock {
+		entry.ref = true
+	} else {
+		g.order.MoveToBack(entry.elem)
+	}
+}
+
+// sweep advances the CLOCK hand to the first slot without its reference bit,
+// clearing the bits it passes, and returns that slot.
+func (g *csGhost[V]) sweep() int {
+	for {
+		entry := g.ring[g.hand]
+		slot := g.hand
+		g.hand = (g.hand + 1) % len(g.ring)
+		if !entry.ref {
+			return slot
+		}
+		entry.ref = false
+	}
+}
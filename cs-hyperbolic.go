@@ -0,0 +1,108 @@
+package table
+
+import "github.com/named-data/ndnd/fw/defn"
+
+// CsHyperbolic is a hyperbolic caching replacement policy for the Content Store.
+// The priority of an entry is the number of times it was inserted or used
+// divided by its time in the Content Store, so popular entries decay as they
+// age without any global aging step. Time is counted in references to the
+// Content Store. Since priorities change continuously, no ordered structure
+// is kept: EvictEntries compares a random sample of resident entries and
+// evicts the one with the lowest priority, and BeforeUse only increments a
+// counter.
+type CsHyperbolic struct {
+	cs      PitCsTable
+	samples int
+	now     uint64
+	entries map[uint64]*hyperbolicEntry
+	set     *csSampleSet
+}
+
+type hyperbolicEntry struct {
+	hits     uint64
+	inserted uint64
+}
+
+// hyperbolicParams are the parameters accepted in the "hyperbolic" section of the
+// Content Store configuration, for example:
+//
+//	hyperbolic:
+//	  samples: 64   # resident entries compared for each eviction
+//	  seed: 1       # seed of the RNG picking the samples
+var hyperbolicParams = []CsPolicyParam{
+	{Name: "samples", Default: 64},
+	{Name: "seed", Default: 1},
+}
+
+func init() {
+	RegisterCsPolicy("hyperbolic", hyperbolicParams, func(params CsPolicyParams) error {
+		return checkCsPolicyMin("samples", float64(params.Int("samples")), 1)
+	}, func(cs PitCsTable, params CsPolicyParams) (CsReplacementPolicy, error) {
+		return NewCsHyperbolic(cs, params.Int("samples"), int64(params.Int("seed"))), nil
+	})
+}
+
+// NewCsHyperbolic creates a new hyperbolic caching replacement policy for the
+// Content Store, comparing the given number of sampled entries on eviction.
+func NewCsHyperbolic(cs PitCsTable, samples int, seed int64) *CsHyperbolic {
+	h := new(CsHyperbolic)
+	h.cs = cs
+	h.samples = max(samples, 1)
+	h.entries = make(map[uint64]*hyperbolicEntry)
+	h.set = newCsSampleSet(seed)
+	return h
+}
+
+// AfterInsert is called after a new entry is inserted into the Content Store.
+func (h *CsHyperbolic) AfterInsert(index uint64, wire []byte, data *defn.FwData) {
+	h.now++
+	if entry, ok := h.entries[index]; ok {
+		entry.hits++
+		return
+	}
+	h.entries[index] = &hyperbolicEntry{hits: 1, inserted: h.now}
+	h.set.add(index)
+}
+
+// AfterRefresh is called after a new data packet refreshes an existing entry in the Content Store.
+func (h *CsHyperbolic) AfterRefresh(index uint64, wire []byte, data *defn.FwData) {
+	h.BeforeUse(index, wire)
+}
+
+// BeforeErase is called before an entry is erased from the Content Store through management.
+func (h *CsHyperbolic) BeforeErase(index uint64, wire []byte) {
+	if _, ok := h.entries[index]; ok {
+		delete(h.entries, index)
+		h.set.remove(index)
+	}
+}
+
+// BeforeUse is called before an entry in the Content Store is used to satisfy a pending Interest.
+func (h *CsHyperbolic) BeforeUse(index uint64, wire []byte) {
+	h.now++
+	if entry, ok := h.entries[index]; ok {
+		entry.hits++
+	}
+}
+
+// EvictEntries is called to instruct the policy to evict enough entries to reduce
+// the Content Store size below its size limit.
+func (h *CsHyperbolic) EvictEntries() {
+	for csOverCapacity(h.cs, len(h.entries)) {
+		var indexToErase uint64
+		lowest := -1.0
+		h.set.sample(h.samples, func(index uint64) {
+			if priority := h.priority(h.entries[index]); lowest < 0 || priority < lowest {
+				indexToErase, lowest = index, priority
+			}
+		})
+		delete(h.entries, indexToErase)
+		h.set.remove(indexToErase)
+		h.cs.eraseCsDataFromReplacementStrategy(indexToErase)
+	}
+}
+
+// priority returns hits divided by the time spent in the Content Store.
+func (h *CsHyperbolic) priority(entry *hyperbolicEntry) float64 {
+	return float64(entry.hits) / float64(h.now-entry.inserted+1)
+}
@@ -0,0 +1,54 @@
+package table
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// TestCsHyperbolicEvictsLowestPriorityOfSample replays the sampling of
+// CsHyperbolic with an RNG of the same seed, and checks that every victim is
+// the entry with the lowest hits/age among the sampled ones.
+func TestCsHyperbolicEvictsLowestPriorityOfSample(t *testing.T) {
+	const (
+		capacity = 50
+		samples  = 8
+		seed     = 3
+	)
+	setTestCsCapacity(t, capacity)
+	cs := &testCsTable{}
+	h := NewCsHyperbolic(cs, samples, seed)
+	twin := rand.New(rand.NewSource(seed))
+
+	rng := rand.New(rand.NewSource(1))
+	evictions := 0
+	for step := range 5000 {
+		index := uint64(rng.ExpFloat64() * 100)
+		if _, ok := h.entries[index]; ok {
+			h.BeforeUse(index, nil)
+			continue
+		}
+		h.AfterInsert(index, nil, nil)
+		if len(h.entries) <= capacity {
+			continue
+		}
+
+		var want uint64
+		lowest := -1.0
+		for range samples {
+			sampled := h.set.indices[twin.Intn(len(h.set.indices))]
+			if priority := h.priority(h.entries[sampled]); lowest < 0 || priority < lowest {
+				want, lowest = sampled, priority
+			}
+		}
+
+		h.EvictEntries()
+		if len(cs.erased) != 1 || cs.erased[0] != want {
+			t.Fatalf("step %d: evicted %v, want %d with priority %v", step, cs.erased, want, lowest)
+		}
+		cs.erased = cs.erased[:0]
+		evictions++
+	}
+	if evictions < 1000 {
+		t.Errorf("only %d evictions on the trace", evictions)
+	}
+}
@@ -0,0 +1,240 @@
+package table
+
+import (
+	"container/heap"
+	"container/list"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+
+	"github.com/named-data/ndnd/fw/defn"
+)
+
+// CsLeCaR is a LeCaR (Learning Cache Replacement) policy for the Content Store.
+// An LRU expert and an LFU expert rank the same resident entries, and each
+// eviction follows one of them, chosen at random according to their weights.
+// Every expert remembers the entries it evicted in its own ghost history.
+// When an entry is inserted again while in an expert's history, that expert
+// made a mistake, and the weight of the other expert grows by a regret that
+// is discounted by how long ago the eviction happened. The Content Store so
+// moves between recency and frequency as the workload changes, without a
+// fixed lambda as in CsLRFU.
+type CsLeCaR struct {
+	cs           PitCsTable
+	learningRate float64
+	discount     float64
+	now          uint64
+	weightLRU    float64
+	weightLFU    float64
+	rng          *rand.Rand
+
+	lru        *list.List // of *lecarEntry, least recently used first
+	lfu        lecarHeap
+	entries    map[uint64]*lecarEntry
+	historyLRU *csGhost[lecarGhost]
+	historyLFU *csGhost[lecarGhost]
+}
+
+type lecarEntry struct {
+	index   uint64
+	freq    int
+	lastUse uint64
+	elem    *list.Element
+	pos     int
+}
+
+type lecarGhost struct {
+	evicted uint64 // time of the eviction
+	freq    int
+}
+
+// lecarHeap orders entries by frequency, then by last use, lowest first.
+type lecarHeap []*lecarEntry
+
+func (h lecarHeap) Len() int { return len(h) }
+func (h lecarHeap) Less(i, j int) bool {
+	if h[i].freq != h[j].freq {
+		return h[i].freq < h[j].freq
+	}
+	return h[i].lastUse < h[j].lastUse
+}
+func (h lecarHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].pos = i
+	h[j].pos = j
+}
+func (h *lecarHeap) Push(x interface{}) {
+	entry := x.(*lecarEntry)
+	entry.pos = len(*h)
+	*h = append(*h, entry)
+}
+func (h *lecarHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	entry := old[n-1]
+	entry.pos = -1
+	*h = old[:n-1]
+	return entry
+}
+
+// lecarParams are the parameters accepted in the "lecar" section of the
+// Content Store configuration, for example:
+//
+//	lecar:
+//	  learning_rate: 0.45   # how fast the expert weights follow their regrets
+//	  discount: 0.0         # per-reference regret discount, 0 for 0.005^(1/capacity)
+//	  history_capacity: 0   # remembered evictions per expert, 0 for the CS capacity
+//	  seed: 1               # seed of the RNG choosing the expert
+var lecarParams = []CsPolicyParam{
+	{Name: "learning_rate", Default: 0.45},
+	{Name: "discount", Default: 0.0},
+	{Name: "history_capacity", Default: 0},
+	{Name: "seed", Default: 1},
+}
+
+func init() {
+	RegisterCsPolicy("lecar", lecarParams, func(params CsPolicyParams) error {
+		var err error
+		if discount := params.Float("discount"); !(discount >= 0.0 && discount < 1.0) {
+			err = fmt.Errorf("parameter %q: %v is not in [0, 1)", "discount", discount)
+		}
+		return errors.Join(
+			checkCsPolicyMin("learning_rate", params.Float("learning_rate"), 0.0),
+			checkCsPolicyMin("history_capacity", float64(params.Int("history_capacity")), 0),
+			err,
+		)
+	}, func(cs PitCsTable, params CsPolicyParams) (CsReplacementPolicy, error) {
+		return NewCsLeCaR(cs, params.Float("learning_rate"), params.Float("discount"),
+			params.Int("history_capacity"), int64(params.Int("seed"))), nil
+	})
+}
+
+// NewCsLeCaR creates a new LeCaR replacement policy for the Content Store.
+// A discount outside (0, 1) uses 0.005^(1/capacity), and a historyCapacity
+// of 0 or less keeps as many evictions per expert as the Content Store holds.
+func NewCsLeCaR(cs PitCsTable, learningRate float64, discount float64, historyCapacity int, seed int64) *CsLeCaR {
+	if discount <= 0.0 || discount >= 1.0 {
+		discount = math.Pow(0.005, 1.0/float64(max(csCapacity(), 1)))
+	}
+	if historyCapacity <= 0 {
+		historyCapacity = csCapacity()
+	}
+	l := new(CsLeCaR)
+	l.cs = cs
+	l.learningRate = learningRate
+	l.discount = discount
+	l.weightLRU = 0.5
+	l.weightLFU = 0.5
+	l.rng = rand.New(rand.NewSource(seed))
+	l.lru = list.New()
+	l.entries = make(map[uint64]*lecarEntry)
+	l.historyLRU = newCsGhost[lecarGhost](historyCapacity, CsGhostLRU)
+	l.historyLFU = newCsGhost[lecarGhost](historyCapacity, CsGhostLRU)
+	return l
+}
+
+// Weights returns the current weights of the LRU and LFU experts.
+func (l *CsLeCaR) Weights() (float64, float64) {
+	return l.weightLRU, l.weightLFU
+}
+
+// Stats returns the expert weights and the size of each history.
+func (l *CsLeCaR) Stats() map[string]float64 {
+	return map[string]float64{
+		"w_lru":       l.weightLRU,
+		"w_lfu":       l.weightLFU,
+		"history_lru": float64(l.historyLRU.Len()),
+		"history_lfu": float64(l.historyLFU.Len()),
+	}
+}
+
+// AfterInsert is called after a new entry is inserted into the Content Store.
+func (l *CsLeCaR) AfterInsert(index uint64, wire []byte, data *defn.FwData) {
+	if _, ok := l.entries[index]; ok {
+		l.use(index)
+		return
+	}
+	l.now++
+
+	entry := &lecarEntry{index: index, freq: 1, lastUse: l.now}
+	if ghost, ok := l.historyLRU.get(index); ok {
+		// LRU should not have evicted it: trust LFU more
+		l.historyLRU.remove(index)
+		entry.freq += ghost.freq
+		l.weightLFU *= math.Exp(l.learningRate * l.regret(ghost))
+		l.normalize()
+	} else if ghost, ok := l.historyLFU.get(index); ok {
+		// LFU should not have evicted it: trust LRU more
+		l.historyLFU.remove(index)
+		entry.freq += ghost.freq
+		l.weightLRU *= math.Exp(l.learningRate * l.regret(ghost))
+		l.normalize()
+	}
+
+	entry.elem = l.lru.PushBack(entry)
+	heap.Push(&l.lfu, entry)
+	l.entries[index] = entry
+}
+
+// AfterRefresh is called after a new data packet refreshes an existing entry in the Content Store.
+func (l *CsLeCaR) AfterRefresh(index uint64, wire []byte, data *defn.FwData) {
+	l.use(index)
+}
+
+// BeforeErase is called before an entry is erased from the Content Store through management.
+func (l *CsLeCaR) BeforeErase(index uint64, wire []byte) {
+	if entry, ok := l.entries[index]; ok {
+		l.remove(entry)
+	}
+}
+
+// BeforeUse is called before an entry in the Content Store is used to satisfy a pending Interest.
+func (l *CsLeCaR) BeforeUse(index uint64, wire []byte) {
+	l.use(index)
+}
+
+// EvictEntries is called to instruct the policy to evict enough entries to reduce
+// the Content Store size below its size limit.
+func (l *CsLeCaR) EvictEntries() {
+	for csOverCapacity(l.cs, len(l.entries)) {
+		victim := l.lru.Front().Value.(*lecarEntry)
+		history := l.historyLRU
+		if l.rng.Float64() >= l.weightLRU {
+			victim = l.lfu[0]
+			history = l.historyLFU
+		}
+		l.remove(victim)
+		history.put(victim.index, lecarGhost{evicted: l.now, freq: victim.freq})
+		l.cs.eraseCsDataFromReplacementStrategy(victim.index)
+	}
+}
+
+func (l *CsLeCaR) use(index uint64) {
+	entry, ok := l.entries[index]
+	if !ok {
+		return
+	}
+	l.now++
+	entry.freq++
+	entry.lastUse = l.now
+	l.lru.MoveToBack(entry.elem)
+	heap.Fix(&l.lfu, entry.pos)
+}
+
+func (l *CsLeCaR) remove(entry *lecarEntry) {
+	l.lru.Remove(entry.elem)
+	heap.Remove(&l.lfu, entry.pos)
+	delete(l.entries, entry.index)
+}
+
+// regret returns the regret of an eviction, discounted by its age.
+func (l *CsLeCaR) regret(ghost lecarGhost) float64 {
+	return math.Pow(l.discount, float64(l.now-ghost.evicted))
+}
+
+func (l *CsLeCaR) normalize() {
+	sum := l.weightLRU + l.weightLFU
+	l.weightLRU /= sum
+	l.weightLFU /= sum
+}
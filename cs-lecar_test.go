@@ -0,0 +1,56 @@
+package table
+
+import (
+	"math"
+	"slices"
+	"testing"
+)
+
+// TestCsLeCaRLearnsFromMistakes checks that when an entry evicted by one expert
+// is inserted again, the weight moves to the other expert by the discounted regret.
+func TestCsLeCaRLearnsFromMistakes(t *testing.T) {
+	tests := []struct {
+		expert string
+		victim uint64
+	}{
+		{"lru", 0}, // the entry used most often, but least recently
+		{"lfu", 1}, // the entry used least often and least recently
+	}
+	for _, test := range tests {
+		t.Run(test.expert, func(t *testing.T) {
+			setTestCsCapacity(t, 2)
+			cs := &testCsTable{}
+			l := NewCsLeCaR(cs, 0.45, 0.9, 0, 1)
+
+			l.AfterInsert(0, nil, nil) // time 1
+			for range 3 {
+				l.BeforeUse(0, nil) // times 2 to 4
+			}
+			l.AfterInsert(1, nil, nil) // time 5
+
+			// Let the expert under test make the eviction
+			if test.expert == "lru" {
+				l.weightLRU, l.weightLFU = 1, 0
+			} else {
+				l.weightLRU, l.weightLFU = 0, 1
+			}
+			l.AfterInsert(2, nil, nil) // time 6
+			l.EvictEntries()
+			if !slices.Equal(cs.erased, []uint64{test.victim}) {
+				t.Fatalf("evicted %v, want %d", cs.erased, test.victim)
+			}
+
+			l.weightLRU, l.weightLFU = 0.5, 0.5
+			l.AfterInsert(test.victim, nil, nil) // time 7, one reference after the eviction
+			grown := math.Exp(0.45*0.9) / (1 + math.Exp(0.45*0.9))
+			wLRU, wLFU := l.Weights()
+			other := wLFU
+			if test.expert == "lfu" {
+				other = wLRU
+			}
+			if math.Abs(other-grown) > 1e-12 || math.Abs(wLRU+wLFU-1) > 1e-12 {
+				t.Errorf("weights are %v for LRU and %v for LFU, want %v for the other expert", wLRU, wLFU, grown)
+			}
+		})
+	}
+}
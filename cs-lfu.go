@@ -1,152 +1,386 @@
 package table
 
 import (
+	"container/heap"
 	"container/list"
-	"fmt"
+	"errors"
+	"math/rand"
 
 	"github.com/named-data/ndnd/fw/defn"
 )
 
+// CsLFU is a Least Frequently Used replacement policy.
+// Entries are grouped into frequency nodes kept in ascending order of frequency,
+// and each node holds its entries from least to most recently used, so that
+// the victim (the oldest entry among those with the minimum frequency) is
+// always at the front of the first node.
+//
+// The frequency of an evicted entry is remembered in a bounded ghost history,
+// so that it continues from there if the entry is inserted again.
+//
+// With dynamic aging (LFU-DA), entries are ordered by the priority L + freq
+// instead, where the inflation value L is raised to the priority of every
+// evicted entry. Formerly popular entries thus lose their advantage over time.
 type CsLFU struct {
 	cs          PitCsTable
-	freq        map[uint64]int
-	historyFreq map[uint64]int // untuk menyimpan riwayat frekuensi
-	queue       *list.List
-	locations   map[uint64]*list.Element
-	bucket      map[int]map[uint64]struct{} // frekuensi -> set index
-	maxPerFreq  int                         // batas jumlah index per frekuensi
+	historyFreq *csGhost[lfuGhostFreq] // untuk menyimpan riwayat frekuensi
+	entries     map[uint64]*lfuEntry
+	freqList    *list.List            // of *lfuFreqNode, ascending key
+	bucket      map[int]*list.Element // key -> node in freqList
+	maxPerFreq  int                   // batas jumlah index per frekuensi
+
+	// overflowVictim selects the victim when the minimum bucket exceeds maxPerFreq.
+	overflowVictim string
+	rng            *rand.Rand
+	additions      uint64 // entries added to nodes so far, orders the entries of bySize
+
+	dynamicAging bool
+	inflation    int // L, the priority of the last evicted entry
+
+	// Remembered frequencies are halved every historyDecay records (0 disables decay).
+	historyDecay   int
+	historyRecords int
+	historyEpoch   uint
+}
+
+// lfuGhostFreq is a frequency remembered in the ghost history.
+type lfuGhostFreq struct {
+	freq  int
+	epoch uint
+}
+
+// lfuFreqNode holds all entries with the same key, which is the frequency,
+// or the priority under dynamic aging.
+type lfuFreqNode struct {
+	key     int
+	entries *list.List // of uint64, least recently used first
+
+	// Only the one needed by the overflow victim choice is kept.
+	slots  []*lfuEntry // for random victims, in no particular order
+	bySize lfuSizeHeap // for largest victims
+}
+
+// lfuEntry locates an index in the frequency list.
+type lfuEntry struct {
+	index uint64
+	freq  int
+	key   int
+	size  int           // length of the wire encoding
+	added uint64        // value of CsLFU.additions when added to the node
+	slot  int           // position in the node's slots or bySize
+	node  *list.Element // in CsLFU.freqList
+	elem  *list.Element // in the node's entries
+}
+
+// lfuSizeHeap orders the entries of a node by size, largest first, and the
+// least recently used first among equals.
+type lfuSizeHeap []*lfuEntry
+
+func (h lfuSizeHeap) Len() int { return len(h) }
+func (h lfuSizeHeap) Less(i, j int) bool {
+	if h[i].size != h[j].size {
+		return h[i].size > h[j].size
+	}
+	return h[i].added < h[j].added
+}
+func (h lfuSizeHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].slot = i
+	h[j].slot = j
+}
+func (h *lfuSizeHeap) Push(x interface{}) {
+	entry := x.(*lfuEntry)
+	entry.slot = len(*h)
+	*h = append(*h, entry)
+}
+func (h *lfuSizeHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	entry := old[n-1]
+	old[n-1] = nil
+	*h = old[:n-1]
+	return entry
+}
+
+// lfuParams are the parameters accepted in the "lfu" section of the
+// Content Store configuration, for example:
+//
+//	lfu:
+//	  history_capacity: 100000  # remembered evicted indices, 0 for the CS capacity
+//	  history_mode: clock       # "lru" (default) or "clock"
+//	  history_decay: 10000      # halve remembered frequencies every N evictions, 0 to disable
+//	  max_per_freq: 900         # entries with the minimum frequency before overflow eviction
+//	                            # random victims cost O(1) per entry update, largest O(log n)
+//	  overflow_victim: largest  # "oldest" (default), "random" or "largest"
+//	  overflow_seed: 1          # seed of the RNG for random overflow victims
+var lfuParams = []CsPolicyParam{
+	{Name: "history_capacity", Default: 0},
+	{Name: "history_mode", Default: CsGhostLRU, Choices: []string{CsGhostLRU, CsGhostClock}},
+	{Name: "history_decay", Default: 0},
+	{Name: "max_per_freq", Default: 900},
+	{Name: "overflow_victim", Default: LfuOverflowOldest, Choices: []string{
+		LfuOverflowOldest, LfuOverflowRandom, LfuOverflowLargest,
+	}},
+	{Name: "overflow_seed", Default: 1},
 }
 
+// Victim choices for CsLFU when the minimum frequency bucket exceeds maxPerFreq.
+const (
+	// LfuOverflowOldest evicts the least recently used entry of the bucket.
+	LfuOverflowOldest = "oldest"
+	// LfuOverflowRandom evicts an entry of the bucket chosen by a seeded RNG.
+	LfuOverflowRandom = "random"
+	// LfuOverflowLargest evicts the entry of the bucket with the largest packet,
+	// the least recently used one among equals.
+	LfuOverflowLargest = "largest"
+)
+
+func init() {
+	RegisterCsPolicy("lfu", lfuParams, validateLfuParams, func(cs PitCsTable, params CsPolicyParams) (CsReplacementPolicy, error) {
+		l := NewCsLFU(cs)
+		l.setHistory(params.Int("history_capacity"), params.String("history_mode"), params.Int("history_decay"))
+		l.setOverflow(params.Int("max_per_freq"), params.String("overflow_victim"), int64(params.Int("overflow_seed")))
+		return l, nil
+	})
+	RegisterCsPolicy("lfu-da", lfuParams, validateLfuParams, func(cs PitCsTable, params CsPolicyParams) (CsReplacementPolicy, error) {
+		l := NewCsLFUDA(cs)
+		l.setHistory(params.Int("history_capacity"), params.String("history_mode"), params.Int("history_decay"))
+		l.setOverflow(params.Int("max_per_freq"), params.String("overflow_victim"), int64(params.Int("overflow_seed")))
+		return l, nil
+	})
+}
+
+// validateLfuParams checks the ranges of lfuParams.
+func validateLfuParams(params CsPolicyParams) error {
+	return errors.Join(
+		checkCsPolicyMin("history_capacity", float64(params.Int("history_capacity")), 0),
+		checkCsPolicyMin("history_decay", float64(params.Int("history_decay")), 0),
+		checkCsPolicyMin("max_per_freq", float64(params.Int("max_per_freq")), 1),
+	)
+}
+
+// NewCsLFU creates a new LFU replacement policy for the Content Store.
 func NewCsLFU(cs PitCsTable) *CsLFU {
 	l := new(CsLFU)
 	l.cs = cs
-	l.queue = list.New()
-	l.locations = make(map[uint64]*list.Element)
-	l.freq = make(map[uint64]int)
-	l.historyFreq = make(map[uint64]int)
-	l.bucket = make(map[int]map[uint64]struct{})
-	l.maxPerFreq = 900
+	l.historyFreq = newCsGhost[lfuGhostFreq](csCapacity(), CsGhostLRU)
+	l.entries = make(map[uint64]*lfuEntry)
+	l.freqList = list.New()
+	l.bucket = make(map[int]*list.Element)
+	l.setOverflow(900, LfuOverflowOldest, 1)
+	return l
+}
+
+// NewCsLFUDA creates a new LFU replacement policy with dynamic aging for the Content Store.
+func NewCsLFUDA(cs PitCsTable) *CsLFU {
+	l := NewCsLFU(cs)
+	l.dynamicAging = true
 	return l
 }
 
-func (l *CsLFU) addToBucket(index uint64, freq int) {
-	if _, ok := l.bucket[freq]; !ok {
-		l.bucket[freq] = make(map[uint64]struct{})
+// setHistory replaces the ghost history. A capacity of 0 uses the CS capacity.
+func (l *CsLFU) setHistory(capacity int, mode string, decay int) {
+	if capacity <= 0 {
+		capacity = csCapacity()
 	}
-	l.bucket[freq][index] = struct{}{}
+	l.historyFreq = newCsGhost[lfuGhostFreq](capacity, mode)
+	l.historyDecay = decay
 }
 
-func (l *CsLFU) removeFromBucket(index uint64, freq int) {
-	if _, ok := l.bucket[freq]; ok {
-		delete(l.bucket[freq], index)
-		if len(l.bucket[freq]) == 0 {
-			delete(l.bucket, freq)
-		}
+// setOverflow sets the bucket size limit and how its victim is chosen.
+// It must be called before any entry is added.
+func (l *CsLFU) setOverflow(maxPerFreq int, victim string, seed int64) {
+	l.maxPerFreq = maxPerFreq
+	l.overflowVictim = victim
+	l.rng = rand.New(rand.NewSource(seed))
+}
+
+// rememberFreq records the frequency of an index leaving the Content Store.
+func (l *CsLFU) rememberFreq(index uint64) {
+	entry, ok := l.entries[index]
+	if !ok {
+		return
+	}
+	l.historyFreq.put(index, lfuGhostFreq{freq: entry.freq, epoch: l.historyEpoch})
+
+	l.historyRecords++
+	if l.historyDecay > 0 && l.historyRecords >= l.historyDecay {
+		l.historyRecords = 0
+		l.historyEpoch++
 	}
 }
 
-func (l *CsLFU) AfterInsert(index uint64, wire []byte, data *defn.FwData) {
-	fmt.Printf("[CsLFU] AfterInsert: Packet with index %d inserted to Content Store\n", index)
+// recallFreq returns the remembered frequency of an index, halved once per
+// decay epoch since it was recorded.
+func (l *CsLFU) recallFreq(index uint64) (int, bool) {
+	ghost, ok := l.historyFreq.get(index)
+	if !ok {
+		return 0, false
+	}
+	shift := l.historyEpoch - ghost.epoch
+	if shift >= 63 {
+		return 0, true
+	}
+	return ghost.freq >> shift, true
+}
 
+// keyFor returns the key under which an entry with the given frequency is ordered.
+func (l *CsLFU) keyFor(freq int) int {
+	if l.dynamicAging {
+		return l.inflation + freq
+	}
+	return freq
+}
+
+// nodeFor returns the node for key, creating it if needed. hint must be a node
+// with a lower key, or nil to search from the front; the search only walks past
+// nodes when an entry is readmitted with its history frequency or, under dynamic
+// aging, when the inflation value has grown since the entry was last used.
+func (l *CsLFU) nodeFor(key int, hint *list.Element) *list.Element {
+	if node, ok := l.bucket[key]; ok {
+		return node
+	}
+
+	after := hint
+	next := l.freqList.Front()
+	if hint != nil {
+		next = hint.Next()
+	}
+	for next != nil && next.Value.(*lfuFreqNode).key < key {
+		after = next
+		next = next.Next()
+	}
+
+	fn := &lfuFreqNode{key: key, entries: list.New()}
+	var node *list.Element
+	if after == nil {
+		node = l.freqList.PushFront(fn)
+	} else {
+		node = l.freqList.InsertAfter(fn, after)
+	}
+	l.bucket[key] = node
+	return node
+}
+
+// addToBucket appends the index to the node for its key as its most recently used entry.
+func (l *CsLFU) addToBucket(index uint64, freq int, size int, hint *list.Element) {
+	key := l.keyFor(freq)
+	node := l.nodeFor(key, hint)
+	fn := node.Value.(*lfuFreqNode)
+	l.additions++
+	entry := &lfuEntry{index: index, freq: freq, key: key, size: size, added: l.additions, node: node}
+	entry.elem = fn.entries.PushBack(index)
+	l.entries[index] = entry
+
+	switch l.overflowVictim {
+	case LfuOverflowRandom:
+		entry.slot = len(fn.slots)
+		fn.slots = append(fn.slots, entry)
+	case LfuOverflowLargest:
+		heap.Push(&fn.bySize, entry)
+	}
+}
+
+// removeFromBucket removes the index from its node, dropping the node if it
+// becomes empty. It returns the node preceding the removed entry's node.
+func (l *CsLFU) removeFromBucket(index uint64) *list.Element {
+	entry, ok := l.entries[index]
+	if !ok {
+		return nil
+	}
+	delete(l.entries, index)
+
+	prev := entry.node.Prev()
+	fn := entry.node.Value.(*lfuFreqNode)
+	fn.entries.Remove(entry.elem)
+	switch l.overflowVictim {
+	case LfuOverflowRandom:
+		last := fn.slots[len(fn.slots)-1]
+		fn.slots[entry.slot] = last
+		last.slot = entry.slot
+		fn.slots[len(fn.slots)-1] = nil
+		fn.slots = fn.slots[:len(fn.slots)-1]
+	case LfuOverflowLargest:
+		heap.Remove(&fn.bySize, entry.slot)
+	}
+	if fn.entries.Len() == 0 {
+		l.freqList.Remove(entry.node)
+		delete(l.bucket, fn.key)
+		return prev
+	}
+	return entry.node
+}
+
+// increment moves the index to the next frequency.
+func (l *CsLFU) increment(index uint64, size int) {
+	entry, ok := l.entries[index]
+	if !ok {
+		return
+	}
+	freq := entry.freq + 1
+	hint := l.removeFromBucket(index)
+	l.addToBucket(index, freq, size, hint)
+}
+
+// overflowVictimOf picks the victim of a bucket that exceeds maxPerFreq.
+func (l *CsLFU) overflowVictimOf(fn *lfuFreqNode) uint64 {
+	switch l.overflowVictim {
+	case LfuOverflowRandom:
+		return fn.slots[l.rng.Intn(len(fn.slots))].index
+	case LfuOverflowLargest:
+		return fn.bySize[0].index
+	default:
+		return fn.entries.Front().Value.(uint64)
+	}
+}
+
+func (l *CsLFU) AfterInsert(index uint64, wire []byte, data *defn.FwData) {
 	// Ambil frekuensi dari history jika ada
 	baseFreq := 1
-	if lastFreq, ok := l.historyFreq[index]; ok {
+	if lastFreq, ok := l.recallFreq(index); ok {
 		baseFreq = lastFreq + 1
+		l.historyFreq.remove(index)
 	}
 
 	// Bersihkan jika index masih ada
-	if oldFreq, ok := l.freq[index]; ok {
-		l.removeFromBucket(index, oldFreq)
-	}
-	if location, ok := l.locations[index]; ok {
-		l.queue.Remove(location)
-	}
+	l.removeFromBucket(index)
 
-	l.freq[index] = baseFreq
-	l.historyFreq[index] = baseFreq
-	l.addToBucket(index, baseFreq)
-	l.locations[index] = l.queue.PushBack(index)
+	l.addToBucket(index, baseFreq, len(wire), nil)
 }
 
 func (l *CsLFU) AfterRefresh(index uint64, wire []byte, data *defn.FwData) {
-	fmt.Printf("[CsLFU] AfterRefresh: Packet with index %d will be refreshed (reused)\n", index)
-	oldFreq := l.freq[index]
-	l.freq[index] = oldFreq + 1
-	l.historyFreq[index] = l.freq[index]
-	l.removeFromBucket(index, oldFreq)
-	l.addToBucket(index, oldFreq+1)
-	if location, ok := l.locations[index]; ok {
-		l.queue.Remove(location)
-	}
-	l.locations[index] = l.queue.PushBack(index)
+	l.increment(index, len(wire))
 }
 
 func (l *CsLFU) BeforeUse(index uint64, wire []byte) {
-	fmt.Printf("[CsLFU] BeforeUse: Packet with index %d will be used\n", index)
-	oldFreq := l.freq[index]
-	l.freq[index] = oldFreq + 1
-	l.historyFreq[index] = l.freq[index]
-	l.removeFromBucket(index, oldFreq)
-	l.addToBucket(index, oldFreq+1)
-	if location, ok := l.locations[index]; ok {
-		l.queue.Remove(location)
+	if entry, ok := l.entries[index]; ok {
+		l.increment(index, entry.size)
 	}
-	l.locations[index] = l.queue.PushBack(index)
 }
 
 func (l *CsLFU) BeforeErase(index uint64, wire []byte) {
-	fmt.Printf("[CsLFU] BeforeErase: Packet with index %d will be deleted from Content Store\n", index)
-	if location, ok := l.locations[index]; ok {
-		l.queue.Remove(location)
-		delete(l.locations, index)
-	}
-	if freqVal, ok := l.freq[index]; ok {
-		l.removeFromBucket(index, freqVal)
-		delete(l.freq, index)
-	}
-	// Jangan hapus dari historyFreq agar frekuensinya tetap terjaga saat index masuk lagi
+	// Simpan ke historyFreq agar frekuensinya tetap terjaga saat index masuk lagi
+	l.rememberFreq(index)
+	l.removeFromBucket(index)
 }
 
 func (l *CsLFU) EvictEntries() {
-	for l.queue.Len() > CfgCsCapacity() {
-		minFreq := l.getMinFrequency()
-
-		if len(l.bucket[minFreq]) > l.maxPerFreq {
-			for indexToErase := range l.bucket[minFreq] {
-				fmt.Printf("[CsLFU] EvictEntries: Deleted the index %d (Frequency is already reaching the limit)\n", indexToErase, minFreq)
-				l.cs.eraseCsDataFromReplacementStrategy(indexToErase)
-				if loc, ok := l.locations[indexToErase]; ok {
-					l.queue.Remove(loc)
-					delete(l.locations, indexToErase)
-				}
-				l.removeFromBucket(indexToErase, minFreq)
-				delete(l.freq, indexToErase)
-				break
-			}
-		} else {
-			for e := l.queue.Front(); e != nil; e = e.Next() {
-				indexToErase := e.Value.(uint64)
-				if l.freq[indexToErase] == minFreq {
-					fmt.Printf("[CsLFU] EvictEntries: Deleted the index %d (eviction reguler)\n", indexToErase)
-					l.cs.eraseCsDataFromReplacementStrategy(indexToErase)
-					l.queue.Remove(e)
-					delete(l.locations, indexToErase)
-					l.removeFromBucket(indexToErase, minFreq)
-					delete(l.freq, indexToErase)
-					break
-				}
-			}
+	for csOverCapacity(l.cs, len(l.entries)) {
+		front := l.freqList.Front()
+		if front == nil {
+			break
 		}
-	}
-}
+		fn := front.Value.(*lfuFreqNode)
+		indexToErase := fn.entries.Front().Value.(uint64)
 
-func (l *CsLFU) getMinFrequency() int {
-	minFreq := int(^uint(0) >> 1)
-	for freq := range l.bucket {
-		if len(l.bucket[freq]) > 0 && freq < minFreq {
-			minFreq = freq
+		if fn.entries.Len() > l.maxPerFreq {
+			indexToErase = l.overflowVictimOf(fn)
 		}
+		if l.dynamicAging {
+			l.inflation = fn.key
+		}
+		l.cs.eraseCsDataFromReplacementStrategy(indexToErase)
+		l.rememberFreq(indexToErase)
+		l.removeFromBucket(indexToErase)
 	}
-	return minFreq
 }
-
@@ -0,0 +1,325 @@
+package table
+
+import (
+	"container/list"
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+// TestCsLFUEvictsOldestOfMinimumFrequency checks every victim of CsLFU against
+// a model that keeps the frequency and last reference of every entry, and the
+// frequency of every evicted entry.
+func TestCsLFUEvictsOldestOfMinimumFrequency(t *testing.T) {
+	const capacity = 50
+	setTestCsCapacity(t, capacity)
+
+	cs := &testCsTable{}
+	l := NewCsLFU(cs)
+	l.setHistory(1000, CsGhostLRU, 0) // remembers every evicted index of the trace
+
+	freq := make(map[uint64]int)
+	lastRef := make(map[uint64]int)
+	history := make(map[uint64]int)
+	rng := rand.New(rand.NewSource(1))
+	for step := 0; step < 20000; step++ {
+		index := uint64(rng.ExpFloat64() * 60)
+		lastRef[index] = step
+		if _, ok := freq[index]; ok {
+			freq[index]++
+			l.BeforeUse(index, nil)
+			continue
+		}
+		freq[index] = history[index] + 1
+		l.AfterInsert(index, nil, nil)
+		if len(freq) <= capacity {
+			continue
+		}
+
+		var want uint64
+		found := false
+		for index, f := range freq {
+			if !found || f < freq[want] || (f == freq[want] && lastRef[index] < lastRef[want]) {
+				want, found = index, true
+			}
+		}
+
+		l.EvictEntries()
+		if len(cs.erased) != 1 || cs.erased[0] != want {
+			t.Fatalf("step %d: evicted %v, want %d with frequency %d", step, cs.erased, want, freq[want])
+		}
+		history[want] = freq[want]
+		delete(freq, want)
+		delete(lastRef, want)
+		cs.erased = cs.erased[:0]
+	}
+}
+
+// TestCsLFUDAAgesOutStaleEntries checks that the inflation value rises to the
+// key of every victim, that new entries start at L + 1, and that an entry made
+// popular once is eventually evicted by a stream of entries used twice, which
+// it is not without dynamic aging.
+func TestCsLFUDAAgesOutStaleEntries(t *testing.T) {
+	setTestCsCapacity(t, 3)
+
+	for _, aging := range []bool{false, true} {
+		cs := &testCsTable{}
+		l := NewCsLFU(cs)
+		if aging {
+			l = NewCsLFUDA(cs)
+		}
+
+		const stale = 0
+		l.AfterInsert(stale, nil, nil)
+		for range 9 {
+			l.BeforeUse(stale, nil)
+		}
+
+		evicted := false
+		for index := uint64(1); index < 100 && !evicted; index++ {
+			l.AfterInsert(index, nil, nil)
+			if aging && l.entries[index].key != l.inflation+1 {
+				t.Fatalf("entry %d has key %d with inflation %d, want L + 1", index, l.entries[index].key, l.inflation)
+			}
+			l.BeforeUse(index, nil)
+
+			minKey := -1
+			for _, entry := range l.entries {
+				if minKey < 0 || entry.key < minKey {
+					minKey = entry.key
+				}
+			}
+			l.EvictEntries()
+			if aging && len(cs.erased) > 0 && l.inflation != minKey {
+				t.Fatalf("inflation is %d after evicting %v, want the victim key %d", l.inflation, cs.erased, minKey)
+			}
+			evicted = slices.Contains(cs.erased, stale)
+			cs.erased = cs.erased[:0]
+		}
+		if evicted != aging {
+			t.Errorf("stale entry evicted: %v with dynamic aging %v", evicted, aging)
+		}
+	}
+}
+
+// lfuOverflowEvictions returns the entries evicted by CsLFU on a fixed trace
+// that overflows the minimum frequency bucket, in order.
+func lfuOverflowEvictions(t *testing.T, victim string, seed int64) []uint64 {
+	setTestCsCapacity(t, 30)
+	cs := &testCsTable{}
+	l := NewCsLFU(cs)
+	l.setOverflow(4, victim, seed)
+
+	rng := rand.New(rand.NewSource(1))
+	for range 5000 {
+		index := uint64(rng.Intn(100))
+		wire := make([]byte, rng.Intn(1500))
+		if _, ok := l.entries[index]; ok {
+			l.BeforeUse(index, wire)
+		} else {
+			l.AfterInsert(index, wire, nil)
+			l.EvictEntries()
+		}
+	}
+	return cs.erased
+}
+
+// TestCsLFUOverflowVictimIsReproducible checks that each overflow victim choice
+// evicts the same entries in the same order when the trace and seed are the same.
+func TestCsLFUOverflowVictimIsReproducible(t *testing.T) {
+	for _, victim := range []string{LfuOverflowOldest, LfuOverflowRandom, LfuOverflowLargest} {
+		t.Run(victim, func(t *testing.T) {
+			first := lfuOverflowEvictions(t, victim, 1)
+			second := lfuOverflowEvictions(t, victim, 1)
+			if !slices.Equal(first, second) {
+				t.Errorf("eviction order differs between two runs with the same seed")
+			}
+		})
+	}
+
+	if slices.Equal(lfuOverflowEvictions(t, LfuOverflowRandom, 1), lfuOverflowEvictions(t, LfuOverflowRandom, 2)) {
+		t.Errorf("random eviction order is the same with different seeds")
+	}
+	if slices.Equal(lfuOverflowEvictions(t, LfuOverflowOldest, 1), lfuOverflowEvictions(t, LfuOverflowLargest, 1)) {
+		t.Errorf("largest eviction order is the same as oldest, overflow was not reached")
+	}
+}
+
+// TestCsLFUOverflowVictim checks the victim of each choice in a bucket of entries
+// with the minimum frequency that exceeds max_per_freq.
+func TestCsLFUOverflowVictim(t *testing.T) {
+	sizes := []int{100, 900, 300, 900, 500}
+	tests := []struct {
+		victim string
+		want   uint64
+	}{
+		{LfuOverflowOldest, 0},
+		{LfuOverflowLargest, 1}, // the least recently used of the two largest
+	}
+	for _, test := range tests {
+		t.Run(test.victim, func(t *testing.T) {
+			setTestCsCapacity(t, len(sizes)-1)
+			cs := &testCsTable{}
+			l := NewCsLFU(cs)
+			l.setOverflow(2, test.victim, 1)
+			for index, size := range sizes {
+				l.AfterInsert(uint64(index), make([]byte, size), nil)
+			}
+			l.EvictEntries()
+			if !slices.Equal(cs.erased, []uint64{test.want}) {
+				t.Errorf("evicted %v, want %d", cs.erased, test.want)
+			}
+
+			// The next victim takes the evicted entry's place.
+			setTestCsCapacity(t, len(sizes)-2)
+			l.EvictEntries()
+			if len(cs.erased) != 2 || cs.erased[1] == test.want {
+				t.Errorf("evicted %v after %d", cs.erased, test.want)
+			}
+		})
+	}
+
+	t.Run(LfuOverflowRandom, func(t *testing.T) {
+		setTestCsCapacity(t, 14)
+		victims := make(map[uint64]bool)
+		for seed := range int64(50) {
+			cs := &testCsTable{}
+			l := NewCsLFU(cs)
+			l.setOverflow(2, LfuOverflowRandom, seed)
+			for index := range uint64(15) {
+				l.AfterInsert(index, nil, nil)
+			}
+			for index := uint64(5); index < 15; index++ {
+				l.BeforeUse(index, nil)
+			}
+			l.EvictEntries()
+			if len(cs.erased) != 1 || cs.erased[0] >= 5 {
+				t.Fatalf("seed %d: evicted %v, want one of the entries 0 to 4 used once", seed, cs.erased)
+			}
+			victims[cs.erased[0]] = true
+		}
+		if len(victims) != 5 {
+			t.Errorf("evicted %v over 50 seeds, want every entry used once", victims)
+		}
+	})
+}
+
+const lfuBenchEntries = 1 << 20
+
+// benchLfuTrace calls insert and use to fill an LFU policy with n entries:
+// the older half is used once more, and the newer half is referenced only
+// once, which is the least recently used half. That is the common state of an
+// LFU Content Store with popular entries and one-time entries streaming
+// through it.
+func benchLfuTrace(n int, insert, use func(index uint64)) {
+	for i := range n / 2 {
+		insert(uint64(i))
+	}
+	for i := range n / 2 {
+		use(uint64(i))
+	}
+	for i := n / 2; i < n; i++ {
+		insert(uint64(i))
+	}
+}
+
+// newBenchCsLFU returns a CsLFU holding n entries, see benchLfuTrace.
+func newBenchCsLFU(n int) *CsLFU {
+	l := NewCsLFU(&testCsTable{})
+	l.setOverflow(n, LfuOverflowOldest, 1) // no overflow eviction
+	benchLfuTrace(n,
+		func(index uint64) { l.AfterInsert(index, nil, nil) },
+		func(index uint64) { l.BeforeUse(index, nil) })
+	return l
+}
+
+func BenchmarkCsLFUInsert(b *testing.B) {
+	setTestCsCapacity(b, lfuBenchEntries+b.N)
+	l := newBenchCsLFU(lfuBenchEntries)
+	b.ResetTimer()
+	for i := range b.N {
+		l.AfterInsert(uint64(lfuBenchEntries+i), nil, nil)
+	}
+}
+
+func BenchmarkCsLFUUse(b *testing.B) {
+	setTestCsCapacity(b, lfuBenchEntries)
+	l := newBenchCsLFU(lfuBenchEntries)
+	rng := rand.New(rand.NewSource(2))
+	b.ResetTimer()
+	for range b.N {
+		l.BeforeUse(uint64(rng.Intn(lfuBenchEntries)), nil)
+	}
+}
+
+func BenchmarkCsLFUEvict(b *testing.B) {
+	l := newBenchCsLFU(lfuBenchEntries + b.N)
+	setTestCsCapacity(b, lfuBenchEntries)
+	b.ResetTimer()
+	l.EvictEntries()
+}
+
+// lfuScan is the eviction of CsLFU before frequency lists: the minimum
+// frequency is found by scanning all frequencies, and its oldest entry by
+// scanning the entries from least to most recently used.
+type lfuScan struct {
+	freq      map[uint64]int
+	queue     *list.List
+	locations map[uint64]*list.Element
+	bucket    map[int]map[uint64]struct{}
+}
+
+func newLfuScan() *lfuScan {
+	return &lfuScan{
+		freq:      make(map[uint64]int),
+		queue:     list.New(),
+		locations: make(map[uint64]*list.Element),
+		bucket:    make(map[int]map[uint64]struct{}),
+	}
+}
+
+func (l *lfuScan) reference(index uint64) {
+	oldFreq, ok := l.freq[index]
+	if ok {
+		delete(l.bucket[oldFreq], index)
+		if len(l.bucket[oldFreq]) == 0 {
+			delete(l.bucket, oldFreq)
+		}
+		l.queue.Remove(l.locations[index])
+	}
+	l.freq[index] = oldFreq + 1
+	if _, ok := l.bucket[oldFreq+1]; !ok {
+		l.bucket[oldFreq+1] = make(map[uint64]struct{})
+	}
+	l.bucket[oldFreq+1][index] = struct{}{}
+	l.locations[index] = l.queue.PushBack(index)
+}
+
+func (l *lfuScan) evict() {
+	minFreq := int(^uint(0) >> 1)
+	for freq := range l.bucket {
+		minFreq = min(minFreq, freq)
+	}
+	for e := l.queue.Front(); e != nil; e = e.Next() {
+		if index := e.Value.(uint64); l.freq[index] == minFreq {
+			l.queue.Remove(e)
+			delete(l.locations, index)
+			delete(l.bucket[minFreq], index)
+			if len(l.bucket[minFreq]) == 0 {
+				delete(l.bucket, minFreq)
+			}
+			delete(l.freq, index)
+			return
+		}
+	}
+}
+
+// BenchmarkCsLFUEvictScan is BenchmarkCsLFUEvict with lfuScan, for comparison.
+func BenchmarkCsLFUEvictScan(b *testing.B) {
+	l := newLfuScan()
+	benchLfuTrace(lfuBenchEntries+b.N, l.reference, l.reference)
+	b.ResetTimer()
+	for range b.N {
+		l.evict()
+	}
+}
@@ -0,0 +1,258 @@
+package table
+
+import (
+	"container/list"
+	"math"
+
+	"github.com/named-data/ndnd/fw/defn"
+)
+
+// CsLIRS is a Low Inter-reference Recency Set (LIRS) replacement policy.
+// Entries with a low inter-reference recency (LIR) make up most of the cache,
+// while a small share of the capacity holds high inter-reference recency (HIR)
+// entries. One-shot scans only cycle through the HIR share, so they cannot
+// flush the LIR set.
+//
+// The stack S keeps LIR entries and recently seen HIR entries, resident or not,
+// in recency order; its bottom is always a LIR entry. The queue Q keeps resident
+// HIR entries, which are the eviction candidates.
+type CsLIRS struct {
+	cs          PitCsTable
+	hirPercent  float64
+	entries     map[uint64]*lirsEntry
+	stack       *list.List // S, of *lirsEntry, bottom (least recent) first
+	queue       *list.List // Q, of *lirsEntry, next victim first
+	nonResident *list.List // of *lirsEntry, oldest first
+	lirCount    int
+	resident    int
+}
+
+type lirsEntry struct {
+	index    uint64
+	lir      bool
+	resident bool
+	sElem    *list.Element // nil if not in S
+	qElem    *list.Element // nil if not in Q
+	nElem    *list.Element // nil if resident
+}
+
+func init() {
+	RegisterCsPolicy("lirs", []CsPolicyParam{
+		{Name: "hir_percent", Default: 1.0},
+	}, func(params CsPolicyParams) error {
+		return checkCsPolicyRange("hir_percent", params.Float("hir_percent"), 0.0, 100.0)
+	}, func(cs PitCsTable, params CsPolicyParams) (CsReplacementPolicy, error) {
+		return NewCsLIRS(cs, params.Float("hir_percent")), nil
+	})
+}
+
+// NewCsLIRS creates a new LIRS replacement policy for the Content Store,
+// reserving hirPercent of the capacity for resident HIR entries.
+func NewCsLIRS(cs PitCsTable, hirPercent float64) *CsLIRS {
+	if hirPercent <= 0.0 {
+		hirPercent = 0.0
+	} else if hirPercent > 100.0 {
+		hirPercent = 100.0
+	}
+	l := new(CsLIRS)
+	l.cs = cs
+	l.hirPercent = hirPercent
+	l.entries = make(map[uint64]*lirsEntry)
+	l.stack = list.New()
+	l.queue = list.New()
+	l.nonResident = list.New()
+	return l
+}
+
+// lirCapacity returns the number of entries reserved for the LIR set.
+// At least one entry is always left for resident HIR entries.
+func (l *CsLIRS) lirCapacity() int {
+	capacity := csCapacity()
+	hir := int(math.Ceil(float64(capacity) * l.hirPercent / 100.0))
+	if hir < 1 {
+		hir = 1
+	}
+	return max(capacity-hir, 0)
+}
+
+// AfterInsert is called after a new entry is inserted into the Content Store.
+func (l *CsLIRS) AfterInsert(index uint64, wire []byte, data *defn.FwData) {
+	entry, ok := l.entries[index]
+	if !ok {
+		entry = &lirsEntry{index: index}
+		l.entries[index] = entry
+	}
+	l.makeResident(entry)
+
+	switch {
+	case entry.lir:
+		// Already LIR (inserted again without an erase), treat as a hit
+		l.access(entry)
+	case l.lirCount < l.lirCapacity():
+		// Warm up: fill the LIR set first
+		l.toTop(entry)
+		l.setLIR(entry)
+	case entry.sElem != nil:
+		// Non-resident HIR entry still in S: its recency is low enough to become LIR
+		l.toTop(entry)
+		l.setLIR(entry)
+		l.demoteBottom()
+	default:
+		l.toTop(entry)
+		entry.qElem = l.queue.PushBack(entry)
+	}
+}
+
+// AfterRefresh is called after a new data packet refreshes an existing entry in the Content Store.
+func (l *CsLIRS) AfterRefresh(index uint64, wire []byte, data *defn.FwData) {
+	if entry, ok := l.entries[index]; ok && entry.resident {
+		l.access(entry)
+	}
+}
+
+// BeforeErase is called before an entry is erased from the Content Store through management.
+func (l *CsLIRS) BeforeErase(index uint64, wire []byte) {
+	entry, ok := l.entries[index]
+	if !ok || !entry.resident {
+		return
+	}
+	if entry.lir {
+		l.lirCount--
+	}
+	l.forget(entry)
+	l.prune()
+}
+
+// BeforeUse is called before an entry in the Content Store is used to satisfy a pending Interest.
+func (l *CsLIRS) BeforeUse(index uint64, wire []byte) {
+	if entry, ok := l.entries[index]; ok && entry.resident {
+		l.access(entry)
+	}
+}
+
+// EvictEntries is called to instruct the policy to evict enough entries to reduce
+// the Content Store size below its size limit.
+func (l *CsLIRS) EvictEntries() {
+	for csOverCapacity(l.cs, l.resident) {
+		if l.queue.Len() == 0 {
+			l.demoteBottom()
+		}
+		entry := l.queue.Remove(l.queue.Front()).(*lirsEntry)
+		entry.qElem = nil
+		l.resident--
+		entry.resident = false
+		if entry.sElem != nil {
+			entry.nElem = l.nonResident.PushBack(entry)
+		} else {
+			delete(l.entries, entry.index)
+		}
+		l.cs.eraseCsDataFromReplacementStrategy(entry.index)
+	}
+
+	// Bound the number of non-resident entries kept in S
+	for l.nonResident.Len() > csCapacity() {
+		l.forget(l.nonResident.Front().Value.(*lirsEntry))
+	}
+}
+
+// access handles a hit on a resident entry.
+func (l *CsLIRS) access(entry *lirsEntry) {
+	switch {
+	case entry.lir:
+		wasBottom := entry.sElem == l.stack.Front()
+		l.toTop(entry)
+		if wasBottom {
+			l.prune()
+		}
+	case entry.sElem != nil:
+		// Resident HIR entry in S: promote to LIR
+		l.queue.Remove(entry.qElem)
+		entry.qElem = nil
+		l.toTop(entry)
+		l.setLIR(entry)
+		l.demoteBottom()
+	default:
+		// Resident HIR entry not in S: stays HIR
+		l.toTop(entry)
+		l.queue.MoveToBack(entry.qElem)
+	}
+}
+
+func (l *CsLIRS) toTop(entry *lirsEntry) {
+	if entry.sElem != nil {
+		l.stack.MoveToBack(entry.sElem)
+	} else {
+		entry.sElem = l.stack.PushBack(entry)
+	}
+}
+
+func (l *CsLIRS) setLIR(entry *lirsEntry) {
+	entry.lir = true
+	l.lirCount++
+}
+
+func (l *CsLIRS) makeResident(entry *lirsEntry) {
+	if entry.resident {
+		return
+	}
+	entry.resident = true
+	l.resident++
+	if entry.nElem != nil {
+		l.nonResident.Remove(entry.nElem)
+		entry.nElem = nil
+	}
+}
+
+// demoteBottom turns the LIR entry at the bottom of S into a resident HIR entry
+// at the end of Q, then prunes the stack.
+func (l *CsLIRS) demoteBottom() {
+	front := l.stack.Front()
+	if front == nil {
+		return
+	}
+	entry := front.Value.(*lirsEntry)
+	if entry.lir {
+		entry.lir = false
+		l.lirCount--
+		l.stack.Remove(front)
+		entry.sElem = nil
+		entry.qElem = l.queue.PushBack(entry)
+	}
+	l.prune()
+}
+
+// prune removes HIR entries from the bottom of S until a LIR entry is there.
+func (l *CsLIRS) prune() {
+	for front := l.stack.Front(); front != nil; front = l.stack.Front() {
+		entry := front.Value.(*lirsEntry)
+		if entry.lir {
+			return
+		}
+		l.stack.Remove(front)
+		entry.sElem = nil
+		if !entry.resident {
+			l.forget(entry)
+		}
+	}
+}
+
+// forget removes the entry from every structure.
+func (l *CsLIRS) forget(entry *lirsEntry) {
+	if entry.sElem != nil {
+		l.stack.Remove(entry.sElem)
+		entry.sElem = nil
+	}
+	if entry.qElem != nil {
+		l.queue.Remove(entry.qElem)
+		entry.qElem = nil
+	}
+	if entry.nElem != nil {
+		l.nonResident.Remove(entry.nElem)
+		entry.nElem = nil
+	}
+	if entry.resident {
+		entry.resident = false
+		l.resident--
+	}
+	delete(l.entries, entry.index)
+}
@@ -0,0 +1,76 @@
+package table
+
+import "testing"
+
+// newTestCsLIRS returns a CsLIRS with a capacity of 10 entries, of which the
+// entries 0 to 8 make up the LIR set, 0 at the bottom of S.
+func newTestCsLIRS(t *testing.T) (*CsLIRS, *testCsTable) {
+	setTestCsCapacity(t, 10)
+	cs := &testCsTable{}
+	l := NewCsLIRS(cs, 10.0)
+	for index := range uint64(9) {
+		l.AfterInsert(index, nil, nil)
+	}
+	for index := range uint64(9) {
+		if entry := l.entries[index]; entry == nil || !entry.lir {
+			t.Fatalf("entry %d is not LIR after warm up", index)
+		}
+	}
+	return l, cs
+}
+
+// TestCsLIRSSurvivesScan checks that a one-pass scan larger than the cache only
+// cycles through the HIR share and leaves the LIR set resident.
+func TestCsLIRSSurvivesScan(t *testing.T) {
+	l, cs := newTestCsLIRS(t)
+	for index := uint64(1000); index < 1100; index++ {
+		l.AfterInsert(index, nil, nil)
+		l.EvictEntries()
+	}
+	if len(cs.erased) != 100-1 {
+		t.Errorf("scan evicted %d entries, want %d", len(cs.erased), 100-1)
+	}
+	for index := range uint64(9) {
+		if entry := l.entries[index]; entry == nil || !entry.resident || !entry.lir {
+			t.Errorf("LIR entry %d did not survive the scan", index)
+		}
+	}
+}
+
+// TestCsLIRSPromotesHIRInStack checks that a HIR entry re-referenced while in S
+// becomes LIR and demotes the LIR entry at the bottom of S, both when it is
+// resident and when it is inserted again after being evicted.
+func TestCsLIRSPromotesHIRInStack(t *testing.T) {
+	l, cs := newTestCsLIRS(t)
+
+	l.AfterInsert(9, nil, nil)
+	if entry := l.entries[9]; entry.lir || entry.sElem == nil || entry.qElem == nil {
+		t.Fatalf("new entry is not a resident HIR entry in S and Q")
+	}
+	l.BeforeUse(9, nil)
+	if !l.entries[9].lir {
+		t.Errorf("resident HIR entry in S is not LIR after a hit")
+	}
+	if entry := l.entries[0]; entry.lir || entry.qElem == nil {
+		t.Errorf("bottom of S was not demoted to a resident HIR entry")
+	}
+
+	l.AfterInsert(10, nil, nil)
+	l.EvictEntries()
+	if len(cs.erased) != 1 || cs.erased[0] != 0 {
+		t.Fatalf("evicted %v, want the demoted entry 0", cs.erased)
+	}
+	l.AfterInsert(11, nil, nil)
+	l.EvictEntries()
+	if entry := l.entries[10]; entry == nil || entry.resident || entry.sElem == nil {
+		t.Fatalf("evicted %v, want 10 to stay in S as a non-resident entry", cs.erased)
+	}
+
+	l.AfterInsert(10, nil, nil)
+	if !l.entries[10].lir {
+		t.Errorf("non-resident HIR entry in S is not LIR when inserted again")
+	}
+	if l.entries[1].lir {
+		t.Errorf("bottom of S was not demoted")
+	}
+}
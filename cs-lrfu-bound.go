@@ -0,0 +1,226 @@
+package table
+
+import (
+	"container/heap"
+	"math"
+)
+
+// If the weighting function does not preserve order, CsLRFU cannot update CRF
+// values from the previous one, and the order of two entries may change over
+// time without any reference to them. Each entry then keeps a bounded history
+// of its references, and the heap is keyed by lower bounds of their CRF:
+//
+//   - The key of an entry is ln CRF at its horizon, a position ahead of now.
+//     Without new references, CRF only decreases, so until now reaches the
+//     horizon the key is a lower bound of the current CRF of the entry.
+//   - The horizon is placed ahead of now by a fraction of the distance since
+//     the previous reference, as CRF changes more slowly as it ages. The expiry
+//     heap orders entries by horizon, and each entry is re-keyed when now
+//     reaches it, which happens a logarithmic number of times while it is idle.
+//   - The victim is found by popping entries in the order of their keys and
+//     computing their current CRF, until the next key exceeds the smallest CRF
+//     found, see popBounded. Entries popped without being evicted get a nearer
+//     horizon, so that they are not popped again at the next eviction. If too
+//     many entries would have to be popped, all entries are scanned instead.
+//
+// The victim is thus always the entry with the smallest CRF computed from the
+// kept histories. That CRF is not exactly the sum over all past references:
+// groups of references whose weight fell below lrfuHistoryEpsilon are
+// forgotten, and old references are merged at their mean position once an
+// entry has lrfuHistoryLimit groups, which underestimates their weight if F is
+// convex. Two entries whose CRF differ by less than these errors may
+// therefore be evicted in either order.
+
+// Reference history limits for weighting functions that do not preserve order.
+// A group of references is forgotten once its weight falls below
+// lrfuHistoryEpsilon, and an entry keeps at most lrfuHistoryLimit groups.
+const (
+	lrfuHistoryEpsilon = 1e-6
+	lrfuHistoryLimit   = 32
+)
+
+// Horizon placement: an entry is re-keyed lrfuHorizonRatio times the distance
+// since its previous reference ahead of now, and at least lrfuHorizonMin.
+// Candidates popped without being evicted get lrfuTightenRatio times their
+// remaining distance to the horizon.
+const (
+	lrfuHorizonRatio = 0.5
+	lrfuHorizonMin   = 1.0
+	lrfuTightenRatio = 0.25
+)
+
+// lrfuScanLimit bounds the entries popped for one eviction. If it is reached,
+// the victim is found by scanning all entries instead.
+const lrfuScanLimit = 64
+
+// lrfuRef is a group of count references at the same position. Once an entry
+// has lrfuHistoryLimit groups, old references are merged at their mean
+// position, see CsLRFU.appendHistory.
+type lrfuRef struct {
+	pos   float64
+	count float64
+}
+
+// lrfuExpiry orders the entries of a bounded heap by their horizon, nearest first.
+type lrfuExpiry []*HeapEntry
+
+func (h lrfuExpiry) Len() int           { return len(h) }
+func (h lrfuExpiry) Less(i, j int) bool { return h[i].horizon < h[j].horizon }
+func (h lrfuExpiry) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].expiry = i
+	h[j].expiry = j
+}
+func (h *lrfuExpiry) Push(x interface{}) {
+	entry := x.(*HeapEntry)
+	entry.expiry = len(*h)
+	*h = append(*h, entry)
+}
+func (h *lrfuExpiry) Pop() interface{} {
+	old := *h
+	n := len(old)
+	entry := old[n-1]
+	entry.expiry = -1
+	*h = old[:n-1]
+	return entry
+}
+
+// appendHistory adds a reference at the position now to a history, first
+// forgetting the groups of references whose weight is negligible.
+// If the history then has more than lrfuHistoryLimit groups, the two adjacent
+// groups that are closest relative to their age are merged at their mean
+// position. Older groups thus span longer periods, as F changes more slowly
+// there, and the CRF stays close to the sum over all references.
+func (l *CsLRFU) appendHistory(history []lrfuRef, now float64) []lrfuRef {
+	drop := 0
+	for drop < len(history) && lrfuLogWeight(l.weight, history[drop], now) < math.Log(lrfuHistoryEpsilon) {
+		drop++
+	}
+	if drop > 0 {
+		history = append(history[:0], history[drop:]...)
+	}
+	history = append(history, lrfuRef{pos: now, count: 1})
+	if len(history) <= lrfuHistoryLimit {
+		return history
+	}
+
+	merge := 0
+	closest := math.Inf(1)
+	for i := 0; i+1 < len(history); i++ {
+		a, b := history[i], history[i+1]
+		if spread := (b.pos - a.pos) / (now - b.pos + 1); spread < closest {
+			merge, closest = i, spread
+		}
+	}
+	a, b := history[merge], history[merge+1]
+	count := a.count + b.count
+	history[merge] = lrfuRef{pos: (a.pos*a.count + b.pos*b.count) / count, count: count}
+	return append(history[:merge+1], history[merge+2:]...)
+}
+
+// lrfuLogWeight returns ln of the weight of a group of references at the position now.
+func lrfuLogWeight(weight WeightFunction, ref lrfuRef, now float64) float64 {
+	return weight.LogWeight(now-ref.pos) + math.Log(ref.count)
+}
+
+// lrfuLogSum returns ln of the sum of F(now - ref) over the references in history.
+func lrfuLogSum(weight WeightFunction, history []lrfuRef, now float64) float64 {
+	sum := math.Inf(-1)
+	for _, ref := range history {
+		w := lrfuLogWeight(weight, ref, now)
+		if w > sum {
+			sum, w = w, sum
+		}
+		sum += logOnePlusExp(w - sum) // ln(e^sum + e^w), sum being the larger
+	}
+	return sum
+}
+
+// checkHorizon re-keys the entries whose horizon now reaches.
+func (l *CsLRFU) checkHorizon(now float64) {
+	for len(l.expiry) > 0 && l.expiry[0].horizon <= now {
+		entry := l.expiry[0]
+		l.rekey(entry, now, now-entry.ref)
+	}
+}
+
+// key recomputes the key of an entry at a new horizon. The caller fixes the heaps.
+func (l *CsLRFU) key(entry *HeapEntry, horizon float64) {
+	entry.horizon = horizon
+	entry.logCRF = lrfuLogSum(l.weight, entry.history, horizon)
+}
+
+// rekey moves the horizon of an entry in the heap ahead of now, by a fraction
+// of distance.
+func (l *CsLRFU) rekey(entry *HeapEntry, now float64, distance float64) {
+	l.key(entry, now+max(lrfuHorizonRatio*distance, lrfuHorizonMin))
+	heap.Fix(&l.heapList, entry.pos)
+	heap.Fix(&l.expiry, entry.expiry)
+}
+
+// rekeyAll recomputes the horizon and key of every entry, for a new weighting
+// function.
+func (l *CsLRFU) rekeyAll(now float64) {
+	for _, entry := range l.heapList.entries {
+		l.key(entry, now+max(lrfuHorizonRatio*(now-entry.ref), lrfuHorizonMin))
+	}
+	heap.Init(&l.heapList)
+	heap.Init(&l.expiry)
+}
+
+// popBounded removes and returns the entry with the smallest current CRF
+// from a bounded heap, and its ln CRF. Entries are popped in the order of
+// their keys until the next key exceeds the smallest CRF found; as keys are
+// lower bounds, the remaining entries cannot have a smaller CRF. If that takes
+// more than lrfuScanLimit entries, they are pushed back and all entries are
+// scanned. Ties go to the least recently referenced entry.
+func (l *CsLRFU) popBounded(now float64) (*HeapEntry, float64) {
+	l.checkHorizon(now)
+	var victim *HeapEntry
+	minLogCRF := math.Inf(1)
+	l.candidates = l.candidates[:0]
+	for l.heapList.Len() > 0 && l.heapList.entries[0].logCRF <= minLogCRF {
+		if len(l.candidates) == lrfuScanLimit {
+			victim = nil
+			break
+		}
+		entry := heap.Pop(&l.heapList).(*HeapEntry)
+		l.candidates = append(l.candidates, entry)
+		logCRF := lrfuLogSum(l.weight, entry.history, now)
+		if victim == nil || logCRF < minLogCRF || (logCRF == minLogCRF && entry.ref < victim.ref) {
+			victim = entry
+			minLogCRF = logCRF
+		}
+	}
+	for _, entry := range l.candidates {
+		if entry != victim {
+			l.key(entry, now+lrfuTightenRatio*(entry.horizon-now))
+			heap.Push(&l.heapList, entry)
+			heap.Fix(&l.expiry, entry.expiry)
+		}
+	}
+	clear(l.candidates)
+	if victim == nil {
+		victim, minLogCRF = l.scanMinCRF(now)
+		heap.Remove(&l.heapList, victim.pos)
+	}
+	heap.Remove(&l.expiry, victim.expiry)
+	return victim, minLogCRF
+}
+
+// scanMinCRF returns the entry of a bounded heap with the smallest current
+// CRF, and its ln CRF, by computing the CRF of every entry.
+// Ties go to the least recently referenced entry.
+func (l *CsLRFU) scanMinCRF(now float64) (*HeapEntry, float64) {
+	l.fullScans++
+	var victim *HeapEntry
+	minLogCRF := math.Inf(1)
+	for _, entry := range l.heapList.entries {
+		logCRF := lrfuLogSum(l.weight, entry.history, now)
+		if victim == nil || logCRF < minLogCRF || (logCRF == minLogCRF && entry.ref < victim.ref) {
+			victim = entry
+			minLogCRF = logCRF
+		}
+	}
+	return victim, minLogCRF
+}
@@ -0,0 +1,247 @@
+package table
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+// Weighting function families for CsLRFU, selected by the "weight" parameter.
+const (
+	// LrfuWeightExponential is F(x) = p^(λx), the weighting of the original LRFU paper.
+	LrfuWeightExponential = "exponential"
+	// LrfuWeightH3 is F(x) = (e/4)^(λ·ln(x+1)).
+	LrfuWeightH3 = "h3"
+	// LrfuWeightHyperbolic is F(x) = 1 / (1 + λx).
+	LrfuWeightHyperbolic = "hyperbolic"
+	// LrfuWeightCustom is F(x) = p^(λ·x^k).
+	LrfuWeightCustom = "custom"
+)
+
+// Decay modes for CsLRFU, selected by the "decay" parameter.
+const (
+	// LrfuDecayCount measures reference distance with a logical reference counter.
+	LrfuDecayCount = "count"
+	// LrfuDecayTime measures reference distance in wall-clock time, scaled so
+	// that a reference weighs 1/2 after the configured half-life.
+	LrfuDecayTime = "time"
+)
+
+// lrfuParams are the parameters accepted in the "lrfu" section of the
+// Content Store configuration, for example:
+//
+//	lrfu:
+//	  lambda: 0.5       # 0.0 behaves like LFU, 1.0 like LRU
+//	  weight: exponential
+//	  base: 0.25        # p, for exponential and custom
+//	  exponent: 1.0     # k, for custom only
+//	  decay: time       # "count" (default) or "time"
+//	  half_life: 30.0   # seconds after which a reference weighs 1/2, for time decay only
+var lrfuParams = []CsPolicyParam{
+	{Name: "lambda", Default: 0.5},
+	{Name: "weight", Default: LrfuWeightExponential, Choices: []string{
+		LrfuWeightExponential, LrfuWeightH3, LrfuWeightHyperbolic, LrfuWeightCustom,
+	}},
+	{Name: "base", Default: 0.5},
+	{Name: "exponent", Default: 1.0},
+	{Name: "decay", Default: LrfuDecayCount, Choices: []string{LrfuDecayCount, LrfuDecayTime}},
+	{Name: "half_life", Default: 60.0},
+}
+
+func init() {
+	RegisterCsPolicy("lrfu", lrfuParams, validateLrfuParams, func(cs PitCsTable, params CsPolicyParams) (CsReplacementPolicy, error) {
+		makeWeight := lrfuWeightMaker(params)
+		return NewCsLRFU(cs, makeWeight(params.Float("lambda")), lrfuTimeUnit(params)), nil
+	})
+}
+
+// validateLrfuParams checks the ranges of lrfuParams. Parameters that do not
+// apply to the selected weight or decay must still be valid.
+func validateLrfuParams(params CsPolicyParams) error {
+	var errs []error
+	errs = append(errs, checkCsPolicyRange("lambda", params.Float("lambda"), 0.0, 1.0))
+	if base := params.Float("base"); !(base > 0.0 && base < 1.0) {
+		errs = append(errs, fmt.Errorf("parameter %q: %v is not in (0, 1)", "base", base))
+	}
+	if exponent := params.Float("exponent"); !(exponent > 0.0) {
+		errs = append(errs, fmt.Errorf("parameter %q: %v is not positive", "exponent", exponent))
+	}
+	if params.String("decay") == LrfuDecayTime {
+		if halfLife := params.Float("half_life"); !(halfLife > 0.0) {
+			errs = append(errs, fmt.Errorf("parameter %q: %v is not positive", "half_life", halfLife))
+		}
+		if params.Float("lambda") == 0.0 {
+			errs = append(errs, fmt.Errorf("parameter %q: time decay needs a positive lambda", "lambda"))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// lrfuWeightMaker returns a constructor for the configured weighting function
+// family, leaving lambda open so that it can be tuned at runtime.
+func lrfuWeightMaker(params CsPolicyParams) func(lambda float64) WeightFunction {
+	family := params.String("weight")
+	base := params.Float("base")
+	exponent := params.Float("exponent")
+	return func(lambda float64) WeightFunction {
+		return newWeightFunction(family, lambda, base, exponent)
+	}
+}
+
+// lrfuTimeUnit returns the time unit of distances for time decay, or zero for
+// count decay. It is chosen so that F(half_life) = 1/2 for the configured
+// weighting function and lambda; CsALRFU keeps it when lambda changes.
+func lrfuTimeUnit(params CsPolicyParams) time.Duration {
+	if params.String("decay") != LrfuDecayTime {
+		return 0
+	}
+	halfDistance := lrfuHalfDistance(lrfuWeightMaker(params)(params.Float("lambda")))
+	return time.Duration(params.Float("half_life") * float64(time.Second) / halfDistance)
+}
+
+// lrfuHalfDistance returns the distance x at which F(x) = 1/2, found by
+// bisection as F is decreasing, or +Inf if F never falls to 1/2.
+func lrfuHalfDistance(weight WeightFunction) float64 {
+	target := math.Log(0.5)
+	lo, hi := 0.0, 1.0
+	for weight.LogWeight(hi) > target {
+		lo, hi = hi, 2*hi
+		if math.IsInf(hi, 1) {
+			return hi
+		}
+	}
+	for range 100 {
+		mid := (lo + hi) / 2
+		if weight.LogWeight(mid) > target {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return hi
+}
+
+// WeightFunction is the weighting function F(x) of CsLRFU, where x is the
+// distance between a past reference and the current time, counted either in
+// references or in time units (see CsLRFU.now).
+type WeightFunction interface {
+	// Weight returns F(delta). F(0) must be 1.
+	Weight(delta float64) float64
+
+	// LogWeight returns ln F(delta) without underflowing for large distances.
+	LogWeight(delta float64) float64
+
+	// PreservesOrder reports whether F(a+b) = F(a)F(b). If so, decaying all CRF
+	// values by the same distance never changes their relative order.
+	PreservesOrder() bool
+}
+
+// ExponentialWeight is F(x) = Base^(Lambda·x).
+type ExponentialWeight struct {
+	Base   float64
+	Lambda float64
+}
+
+func (w ExponentialWeight) Weight(delta float64) float64 {
+	return math.Pow(w.Base, w.Lambda*delta)
+}
+
+func (w ExponentialWeight) LogWeight(delta float64) float64 {
+	return w.Lambda * delta * math.Log(w.Base)
+}
+
+func (w ExponentialWeight) PreservesOrder() bool {
+	return true
+}
+
+// H3Weight is F(x) = (e/4)^(Lambda·ln(x+1)).
+type H3Weight struct {
+	Lambda float64
+}
+
+func (w H3Weight) Weight(delta float64) float64 {
+	if delta == 0 {
+		return 1.0
+	}
+	return math.Pow(math.E/4.0, w.Lambda*math.Log(delta+1))
+}
+
+func (w H3Weight) LogWeight(delta float64) float64 {
+	return w.Lambda * math.Log(delta+1) * math.Log(math.E/4.0)
+}
+
+func (w H3Weight) PreservesOrder() bool {
+	return false
+}
+
+// HyperbolicWeight is F(x) = 1 / (1 + Lambda·x).
+type HyperbolicWeight struct {
+	Lambda float64
+}
+
+func (w HyperbolicWeight) Weight(delta float64) float64 {
+	return 1.0 / (1.0 + w.Lambda*delta)
+}
+
+func (w HyperbolicWeight) LogWeight(delta float64) float64 {
+	return -math.Log1p(w.Lambda * delta)
+}
+
+func (w HyperbolicWeight) PreservesOrder() bool {
+	return false
+}
+
+// PowerWeight is F(x) = Base^(Lambda·x^Exponent).
+type PowerWeight struct {
+	Base     float64
+	Lambda   float64
+	Exponent float64
+}
+
+func (w PowerWeight) Weight(delta float64) float64 {
+	return math.Pow(w.Base, w.Lambda*math.Pow(delta, w.Exponent))
+}
+
+func (w PowerWeight) LogWeight(delta float64) float64 {
+	return w.Lambda * math.Pow(delta, w.Exponent) * math.Log(w.Base)
+}
+
+func (w PowerWeight) PreservesOrder() bool {
+	return w.Exponent == 1.0
+}
+
+// WeightH1 is the H1 variant of LRFU, F(x) = (1/2)^(λx).
+func WeightH1(lambda float64) WeightFunction {
+	return ExponentialWeight{Base: 0.5, Lambda: clampLambda(lambda)}
+}
+
+// WeightH3 is the H3 variant of LRFU, F(x) = (e/4)^(λ·ln(x+1)).
+func WeightH3(lambda float64) WeightFunction {
+	return H3Weight{Lambda: clampLambda(lambda)}
+}
+
+// newWeightFunction creates the weighting function named by the "weight" parameter.
+func newWeightFunction(family string, lambda, base, exponent float64) WeightFunction {
+	lambda = clampLambda(lambda)
+	switch family {
+	case LrfuWeightH3:
+		return H3Weight{Lambda: lambda}
+	case LrfuWeightHyperbolic:
+		return HyperbolicWeight{Lambda: lambda}
+	case LrfuWeightCustom:
+		return PowerWeight{Base: base, Lambda: lambda, Exponent: exponent}
+	default:
+		return ExponentialWeight{Base: base, Lambda: lambda}
+	}
+}
+
+// clampLambda clamps lambda to [0.0, 1.0].
+func clampLambda(lambda float64) float64 {
+	if lambda < 0.0 {
+		return 0.0
+	} else if lambda > 1.0 {
+		return 1.0
+	}
+	return lambda
+}
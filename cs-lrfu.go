@@ -0,0 +1,335 @@
+package table
+
+import (
+	"container/heap"
+	"container/list"
+	"math"
+	"time"
+
+	"github.com/named-data/ndnd/fw/core"
+	"github.com/named-data/ndnd/fw/defn"
+)
+
+// =========================
+// Heap implementation
+// =========================
+type HeapEntry struct {
+	index  uint64
+	logCRF float64 // ln CRF as of the last reference, or its lower bound if bounded
+	ref    float64 // position of the last reference
+	pos    int     // posisi di heap
+
+	// Only kept if the weighting function does not preserve order, see cs-lrfu-bound.go.
+	history []lrfuRef // past references, oldest first
+	horizon float64   // position up to which logCRF is a lower bound
+	expiry  int       // position in CsLRFU.expiry
+}
+
+// MinHeap orders entries by their CRF decayed to a common position.
+// The order is only stable over time if the weighting function preserves it;
+// if it does not, the heap is bounded instead, see CsLRFU.popBounded.
+type MinHeap struct {
+	entries []*HeapEntry
+	weight  WeightFunction
+	now     float64
+	bounded bool
+}
+
+func (h *MinHeap) logCRFAt(e *HeapEntry) float64 {
+	if h.bounded {
+		return e.logCRF
+	}
+	return h.weight.LogWeight(h.now-e.ref) + e.logCRF
+}
+
+func (h *MinHeap) Len() int           { return len(h.entries) }
+func (h *MinHeap) Less(i, j int) bool { return h.logCRFAt(h.entries[i]) < h.logCRFAt(h.entries[j]) }
+func (h *MinHeap) Swap(i, j int) {
+	h.entries[i], h.entries[j] = h.entries[j], h.entries[i]
+	h.entries[i].pos = i
+	h.entries[j].pos = j
+}
+func (h *MinHeap) Push(x interface{}) {
+	n := len(h.entries)
+	item := x.(*HeapEntry)
+	item.pos = n
+	h.entries = append(h.entries, item)
+}
+func (h *MinHeap) Pop() interface{} {
+	old := h.entries
+	n := len(old)
+	item := old[n-1]
+	item.pos = -1
+	h.entries = old[0 : n-1]
+	return item
+}
+
+// =========================
+// CsLRFU policy
+// =========================
+
+// lrfuRenormalizeLimit is the position on the decay axis after which all
+// positions are shifted back to zero, see CsLRFU.renormalize.
+const lrfuRenormalizeLimit = 1 << 30
+
+// CsLRFU is a Least Recently/Frequently Used replacement policy.
+// Each entry keeps a Combined Recency and Frequency (CRF) value, the sum of
+// F(x) over its past references, where F is the configured WeightFunction
+// and x is the distance from that reference to now.
+//
+// CRF values are stored as natural logarithms, so entries that have not been
+// referenced for a long time keep distinct values instead of underflowing to 0.
+//
+// If the weighting function preserves order (F(a+b) = F(a)F(b)), each CRF
+// value is updated in O(1) from the previous one, and a heap ordered by CRF
+// values decayed to the same position stays valid over time. Otherwise, CRF
+// is summed over a bounded history of past references, and the heap is keyed
+// by lower bounds that are recomputed periodically (see cs-lrfu-bound.go).
+//
+// A CsLRFU without a table only tracks metadata; CsALRFU uses this for its
+// shadow caches.
+type CsLRFU struct {
+	cs        PitCsTable
+	weight    WeightFunction
+	count     uint
+	timeUnit  time.Duration // decay by wall-clock time if non-zero
+	epoch     time.Time
+	logCRF    map[uint64]float64 // ln CRF as of the last reference
+	lastRef   map[uint64]float64 // value of now() at the last reference
+	queue     *list.List
+	locations map[uint64]*list.Element
+
+	heapList MinHeap
+	heapMap  map[uint64]*HeapEntry
+
+	// State of the bounded heap, see cs-lrfu-bound.go.
+	expiry     lrfuExpiry
+	candidates []*HeapEntry
+	fullScans  uint // evictions that scanned all entries
+}
+
+// NewCsLRFU creates a new LRFU replacement policy using the given weighting function.
+// If timeUnit is zero, the distance between references is counted in references;
+// otherwise it is the wall-clock time elapsed, measured in multiples of timeUnit.
+func NewCsLRFU(cs PitCsTable, weight WeightFunction, timeUnit time.Duration) *CsLRFU {
+	return &CsLRFU{
+		cs:        cs,
+		weight:    weight,
+		timeUnit:  timeUnit,
+		epoch:     time.Now(),
+		logCRF:    make(map[uint64]float64),
+		lastRef:   make(map[uint64]float64),
+		queue:     list.New(),
+		locations: make(map[uint64]*list.Element),
+		heapList:  MinHeap{weight: weight, bounded: !weight.PreservesOrder()},
+		heapMap:   make(map[uint64]*HeapEntry),
+	}
+}
+
+// Stats returns the number of evictions that scanned all entries, which only
+// happens if the weighting function does not preserve order.
+func (l *CsLRFU) Stats() map[string]float64 {
+	return map[string]float64{
+		"full_scans": float64(l.fullScans),
+	}
+}
+
+// now returns the current position on the decay axis: the reference counter,
+// or the number of time units elapsed since the epoch.
+func (l *CsLRFU) now() float64 {
+	if l.timeUnit > 0 {
+		return float64(time.Since(l.epoch)) / float64(l.timeUnit)
+	}
+	return float64(l.count)
+}
+
+// tick advances the reference counter and returns the current position.
+func (l *CsLRFU) tick() float64 {
+	l.count++
+	now := l.now()
+	if now >= lrfuRenormalizeLimit {
+		l.renormalize(now)
+		now = l.now()
+	}
+	l.checkHorizon(now)
+	return now
+}
+
+// renormalize shifts every position on the decay axis back by shift.
+// Only distances between positions matter, so no CRF value changes, but
+// positions stay small enough for their differences to be exact in float64
+// and the reference counter never wraps.
+func (l *CsLRFU) renormalize(shift float64) {
+	if l.timeUnit > 0 {
+		l.epoch = l.epoch.Add(time.Duration(shift * float64(l.timeUnit)))
+	} else {
+		l.count -= uint(shift)
+	}
+	for index := range l.lastRef {
+		l.lastRef[index] -= shift
+	}
+	for _, entry := range l.heapList.entries {
+		entry.ref -= shift
+		entry.horizon -= shift
+		for i := range entry.history {
+			entry.history[i].pos -= shift
+		}
+	}
+	if l.cs != nil {
+		core.Log.Debug(nil, "Renormalized LRFU positions", "shift", shift)
+	}
+}
+
+// getLogCRF returns ln CRF of the entry decayed to the position now.
+func (l *CsLRFU) getLogCRF(index uint64, now float64) float64 {
+	if l.heapList.bounded {
+		return lrfuLogSum(l.weight, l.heapMap[index].history, now)
+	}
+	return l.weight.LogWeight(now-l.lastRef[index]) + l.logCRF[index]
+}
+
+// reference records a reference to an existing entry.
+func (l *CsLRFU) reference(index uint64) {
+	if !l.contains(index) {
+		return
+	}
+	now := l.tick()
+	if !l.heapList.bounded {
+		// C(t) = F(0) + F(t - lastRef) * C(lastRef), with F(0) = 1
+		l.logCRF[index] = logOnePlusExp(l.getLogCRF(index, now))
+	}
+	l.lastRef[index] = now
+	if loc, ok := l.locations[index]; ok {
+		l.queue.Remove(loc)
+	}
+	l.locations[index] = l.queue.PushBack(index)
+
+	// update heap
+	if entry, ok := l.heapMap[index]; ok {
+		l.heapList.now = now
+		if l.heapList.bounded {
+			previous := entry.ref
+			entry.ref = now
+			entry.history = l.appendHistory(entry.history, now)
+			l.logCRF[index] = lrfuLogSum(l.weight, entry.history, now)
+			l.rekey(entry, now, now-previous)
+		} else {
+			entry.ref = now
+			entry.logCRF = l.logCRF[index]
+			heap.Fix(&l.heapList, entry.pos)
+		}
+	}
+}
+
+// logOnePlusExp returns ln(1 + e^x) without overflow for large x.
+func logOnePlusExp(x float64) float64 {
+	if x > 0 {
+		return x + math.Log1p(math.Exp(-x))
+	}
+	return math.Log1p(math.Exp(x))
+}
+
+// contains returns whether the entry is tracked by the policy.
+func (l *CsLRFU) contains(index uint64) bool {
+	_, ok := l.locations[index]
+	return ok
+}
+
+// setWeight replaces the weighting function. The heap is rebuilt because
+// the relative order of entries may differ under the new function.
+// The new function must preserve order if and only if the current one does,
+// as CsALRFU only changes lambda.
+func (l *CsLRFU) setWeight(weight WeightFunction) {
+	l.weight = weight
+	l.heapList.weight = weight
+	l.heapList.now = l.now()
+	if l.heapList.bounded {
+		l.rekeyAll(l.heapList.now)
+	} else {
+		heap.Init(&l.heapList)
+	}
+}
+
+// -------------------- AfterInsert --------------------
+func (l *CsLRFU) AfterInsert(index uint64, wire []byte, data *defn.FwData) {
+	now := l.tick()
+	l.logCRF[index] = 0 // ln F(0)
+	l.lastRef[index] = now
+	l.locations[index] = l.queue.PushBack(index)
+
+	// masukkan ke heap
+	entry := &HeapEntry{index: index, logCRF: 0, ref: now}
+	l.heapList.now = now
+	heap.Push(&l.heapList, entry)
+	l.heapMap[index] = entry
+	if l.heapList.bounded {
+		entry.history = []lrfuRef{{pos: now, count: 1}}
+		heap.Push(&l.expiry, entry)
+		l.rekey(entry, now, 0)
+	}
+}
+
+// -------------------- AfterRefresh --------------------
+func (l *CsLRFU) AfterRefresh(index uint64, wire []byte, data *defn.FwData) {
+	l.reference(index)
+}
+
+// -------------------- BeforeErase --------------------
+func (l *CsLRFU) BeforeErase(index uint64, wire []byte) {
+	if loc, ok := l.locations[index]; ok {
+		l.queue.Remove(loc)
+	}
+	delete(l.logCRF, index)
+	delete(l.lastRef, index)
+	delete(l.locations, index)
+
+	// hapus dari heap
+	if entry, ok := l.heapMap[index]; ok {
+		l.heapList.now = l.now()
+		heap.Remove(&l.heapList, entry.pos)
+		if l.heapList.bounded {
+			heap.Remove(&l.expiry, entry.expiry)
+		}
+		delete(l.heapMap, index)
+	}
+}
+
+// -------------------- BeforeUse --------------------
+func (l *CsLRFU) BeforeUse(index uint64, wire []byte) {
+	l.reference(index)
+}
+
+// -------------------- EvictEntries --------------------
+func (l *CsLRFU) EvictEntries() {
+	for csOverCapacity(l.cs, l.queue.Len()) {
+		if l.heapList.Len() == 0 {
+			break
+		}
+		// ambil CRF terkecil dari heap
+		var item *HeapEntry
+		var minLogCRF float64
+		l.heapList.now = l.now()
+		if l.heapList.bounded {
+			item, minLogCRF = l.popBounded(l.heapList.now)
+		} else {
+			item = heap.Pop(&l.heapList).(*HeapEntry)
+			minLogCRF = l.heapList.logCRFAt(item)
+		}
+		targetIndex := item.index
+
+		// hapus dari semua struktur
+		if loc, ok := l.locations[targetIndex]; ok {
+			l.queue.Remove(loc)
+		}
+		delete(l.logCRF, targetIndex)
+		delete(l.lastRef, targetIndex)
+		delete(l.locations, targetIndex)
+		delete(l.heapMap, targetIndex)
+
+		// shadow caches of CsALRFU have no table and do not log
+		if l.cs != nil {
+			l.cs.eraseCsDataFromReplacementStrategy(targetIndex)
+			core.Log.Debug(nil, "Evicted LRFU entry", "index", targetIndex, "logCRF", minLogCRF)
+		}
+	}
+}
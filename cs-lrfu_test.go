@@ -0,0 +1,149 @@
+package table
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+// lrfuTestWeights are the weighting functions the victim tests run with.
+var lrfuTestWeights = map[string]WeightFunction{
+	"h1":         WeightH1(0.5),
+	"h3":         WeightH3(0.5),
+	"hyperbolic": HyperbolicWeight{Lambda: 0.5},
+	"custom":     PowerWeight{Base: 0.5, Lambda: 0.2, Exponent: 0.5},
+}
+
+// checkLRFUVictims replays steps references of the trace on l, and checks
+// every victim against the minimum of CRF = Σ F(now - tᵢ) computed from the
+// full reference history. It returns the number of evictions.
+func checkLRFUVictims(t *testing.T, l *CsLRFU, cs *testCsTable, steps int, trace func() uint64) int {
+	capacity := csCapacity()
+	refs := make(map[uint64][]float64) // index -> all references
+	evictions := 0
+	for step := 0; step < steps; step++ {
+		index := trace()
+		if _, ok := refs[index]; ok {
+			l.BeforeUse(index, nil)
+		} else {
+			l.AfterInsert(index, nil, nil)
+		}
+		refs[index] = append(refs[index], float64(step))
+		if len(refs) <= capacity {
+			continue
+		}
+
+		crf := func(index uint64) float64 {
+			sum := 0.0
+			for _, ref := range refs[index] {
+				sum += l.weight.Weight(float64(step) - ref)
+			}
+			return sum
+		}
+		minCRF := math.Inf(1)
+		for index := range refs {
+			minCRF = math.Min(minCRF, crf(index))
+		}
+
+		l.EvictEntries()
+		if len(cs.erased) != 1 {
+			t.Fatalf("step %d: evicted %v, want one entry", step, cs.erased)
+		}
+		victim := cs.erased[0]
+		if got := crf(victim); math.Abs(got-minCRF) > 1e-9*minCRF {
+			t.Fatalf("step %d: evicted %d with CRF %v, minimum is %v", step, victim, got, minCRF)
+		}
+		delete(refs, victim)
+		cs.erased = cs.erased[:0]
+		evictions++
+	}
+	return evictions
+}
+
+// TestCsLRFUVictimIsMinimumCRF checks the victims of CsLRFU on a skewed trace
+// while positions are renormalized.
+func TestCsLRFUVictimIsMinimumCRF(t *testing.T) {
+	setTestCsCapacity(t, 200)
+	for name, weight := range lrfuTestWeights {
+		t.Run(name, func(t *testing.T) {
+			cs := &testCsTable{}
+			l := NewCsLRFU(cs, weight, 0)
+			// Start close to the limit, so that positions are renormalized midway.
+			l.count = lrfuRenormalizeLimit - 5000
+
+			rng := rand.New(rand.NewSource(1))
+			evictions := checkLRFUVictims(t, l, cs, 10000, func() uint64 {
+				return uint64(rng.ExpFloat64() * 150) // skewed towards small indices
+			})
+			if l.count >= lrfuRenormalizeLimit-5000 {
+				t.Errorf("positions were not renormalized")
+			}
+			if evictions < 1000 {
+				t.Errorf("only %d evictions", evictions)
+			}
+		})
+	}
+}
+
+// TestCsLRFUVictimWithLongHistories checks the victims of CsLRFU on a trace
+// where a few hot entries are referenced far more than lrfuHistoryLimit times,
+// so that their references are merged, and the other half of the references
+// go to entries seen about once, so that more than lrfuScanLimit entries may
+// have to be popped and all entries are scanned instead.
+func TestCsLRFUVictimWithLongHistories(t *testing.T) {
+	const capacity = 400
+	setTestCsCapacity(t, capacity)
+	for name, weight := range lrfuTestWeights {
+		if weight.PreservesOrder() {
+			continue
+		}
+		t.Run(name, func(t *testing.T) {
+			cs := &testCsTable{}
+			l := NewCsLRFU(cs, weight, 0)
+
+			rng := rand.New(rand.NewSource(1))
+			checkLRFUVictims(t, l, cs, 8000, func() uint64 {
+				if rng.Intn(2) == 0 {
+					return uint64(rng.Intn(capacity / 10))
+				}
+				return uint64(capacity/10 + rng.Intn(capacity*50))
+			})
+
+			merged := false
+			for _, entry := range l.heapMap {
+				for _, ref := range entry.history {
+					merged = merged || ref.count > 1
+				}
+			}
+			if !merged {
+				t.Errorf("no references were merged")
+			}
+			if l.fullScans == 0 {
+				t.Errorf("no eviction scanned all entries")
+			}
+		})
+	}
+}
+
+// TestCsLRFUTimeDecayHalfLife checks that with time decay, a reference weighs
+// 1/2 after half_life seconds for every weighting function.
+func TestCsLRFUTimeDecayHalfLife(t *testing.T) {
+	for _, weight := range []string{LrfuWeightExponential, LrfuWeightH3, LrfuWeightHyperbolic, LrfuWeightCustom} {
+		t.Run(weight, func(t *testing.T) {
+			policy, err := NewCsPolicy("lrfu", &testCsTable{}, map[string]any{
+				"weight": weight, "exponent": 0.5, "decay": LrfuDecayTime, "half_life": 3600.0,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			l := policy.(*CsLRFU)
+			l.AfterInsert(1, nil, nil)
+
+			l.epoch = l.epoch.Add(-time.Hour) // one half-life later
+			if got := math.Exp(l.getLogCRF(1, l.now())); math.Abs(got-0.5) > 1e-3 {
+				t.Errorf("weight after one half-life is %v, want 0.5", got)
+			}
+		})
+	}
+}
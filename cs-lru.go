@@ -0,0 +1,67 @@
+package table
+
+import (
+	"container/list"
+
+	"github.com/named-data/ndnd/fw/defn"
+)
+
+// CsLRU is a least recently used (LRU) replacement policy for the Content Store.
+type CsLRU struct {
+	cs        PitCsTable
+	queue     *list.List
+	locations map[uint64]*list.Element
+}
+
+func init() {
+	RegisterCsPolicy("lru", nil, nil, func(cs PitCsTable, _ CsPolicyParams) (CsReplacementPolicy, error) {
+		return NewCsLRU(cs), nil
+	})
+}
+
+// NewCsLRU creates a new LRU replacement policy for the Content Store.
+func NewCsLRU(cs PitCsTable) *CsLRU {
+	l := new(CsLRU)
+	l.cs = cs
+	l.queue = list.New()
+	l.locations = make(map[uint64]*list.Element)
+	return l
+}
+
+// AfterInsert is called after a new entry is inserted into the Content Store.
+func (l *CsLRU) AfterInsert(index uint64, wire []byte, data *defn.FwData) {
+	l.locations[index] = l.queue.PushBack(index)
+}
+
+// AfterRefresh is called after a new data packet refreshes an existing entry in the Content Store.
+func (l *CsLRU) AfterRefresh(index uint64, wire []byte, data *defn.FwData) {
+	if location, ok := l.locations[index]; ok {
+		l.queue.MoveToBack(location)
+	}
+}
+
+// BeforeErase is called before an entry is erased from the Content Store through management.
+func (l *CsLRU) BeforeErase(index uint64, wire []byte) {
+	if location, ok := l.locations[index]; ok {
+		l.queue.Remove(location)
+		delete(l.locations, index)
+	}
+}
+
+// BeforeUse is called before an entry in the Content Store is used to satisfy a pending Interest.
+func (l *CsLRU) BeforeUse(index uint64, wire []byte) {
+	if location, ok := l.locations[index]; ok {
+		l.queue.MoveToBack(location)
+	}
+}
+
+// EvictEntries is called to instruct the policy to evict enough entries to reduce
+// the Content Store size below its size limit.
+func (l *CsLRU) EvictEntries() {
+	for csOverCapacity(l.cs, l.queue.Len()) {
+		indexToErase := l.queue.Front().Value.(uint64)
+		l.cs.eraseCsDataFromReplacementStrategy(indexToErase)
+		l.queue.Remove(l.queue.Front())
+		delete(l.locations, indexToErase)
+	}
+}
@@ -0,0 +1,159 @@
+package table
+
+import (
+	"container/heap"
+
+	"github.com/named-data/ndnd/fw/defn"
+)
+
+// CsLRUK is an LRU-K replacement policy for the Content Store.
+// Each entry keeps the times of its last K references, counted in references
+// to the Content Store, and the entry whose K-th most recent reference is the
+// oldest is evicted. Entries referenced fewer than K times have an infinite
+// backward K-distance and are evicted first, least recently used first.
+// The reference history of evicted entries is retained for a while, so an
+// entry inserted again soon after its eviction keeps its earlier references.
+type CsLRUK struct {
+	cs      PitCsTable
+	k       int
+	now     uint64
+	queue   lrukHeap
+	entries map[uint64]*lrukEntry
+	history *csGhost[[]uint64]
+}
+
+type lrukEntry struct {
+	index uint64
+	refs  []uint64 // times of the last K references, most recent first
+	pos   int
+}
+
+// kth returns the time of the K-th most recent reference, 0 if there were fewer.
+func (e *lrukEntry) kth(k int) uint64 {
+	if len(e.refs) < k {
+		return 0
+	}
+	return e.refs[k-1]
+}
+
+// lrukHeap orders entries by the time of their K-th most recent reference,
+// then by their last reference, oldest first.
+type lrukHeap struct {
+	entries []*lrukEntry
+	k       int
+}
+
+func (h *lrukHeap) Len() int { return len(h.entries) }
+func (h *lrukHeap) Less(i, j int) bool {
+	ki, kj := h.entries[i].kth(h.k), h.entries[j].kth(h.k)
+	if ki != kj {
+		return ki < kj
+	}
+	return h.entries[i].refs[0] < h.entries[j].refs[0]
+}
+func (h *lrukHeap) Swap(i, j int) {
+	h.entries[i], h.entries[j] = h.entries[j], h.entries[i]
+	h.entries[i].pos = i
+	h.entries[j].pos = j
+}
+func (h *lrukHeap) Push(x interface{}) {
+	entry := x.(*lrukEntry)
+	entry.pos = len(h.entries)
+	h.entries = append(h.entries, entry)
+}
+func (h *lrukHeap) Pop() interface{} {
+	old := h.entries
+	n := len(old)
+	entry := old[n-1]
+	entry.pos = -1
+	h.entries = old[:n-1]
+	return entry
+}
+
+func init() {
+	RegisterCsPolicy("lru-k", []CsPolicyParam{
+		{Name: "k", Default: 2},
+	}, func(params CsPolicyParams) error {
+		return checkCsPolicyMin("k", float64(params.Int("k")), 1)
+	}, func(cs PitCsTable, params CsPolicyParams) (CsReplacementPolicy, error) {
+		return NewCsLRUK(cs, params.Int("k")), nil
+	})
+}
+
+// NewCsLRUK creates a new LRU-K replacement policy for the Content Store.
+// A k of 1 or less behaves like LRU.
+func NewCsLRUK(cs PitCsTable, k int) *CsLRUK {
+	l := new(CsLRUK)
+	l.cs = cs
+	l.k = max(k, 1)
+	l.queue.k = l.k
+	l.entries = make(map[uint64]*lrukEntry)
+	l.history = newCsGhost[[]uint64](csCapacity(), CsGhostLRU)
+	return l
+}
+
+// AfterInsert is called after a new entry is inserted into the Content Store.
+func (l *CsLRUK) AfterInsert(index uint64, wire []byte, data *defn.FwData) {
+	if _, ok := l.entries[index]; ok {
+		l.reference(index)
+		return
+	}
+	entry := &lrukEntry{index: index}
+	if refs, ok := l.history.get(index); ok {
+		entry.refs = refs
+		l.history.remove(index)
+	}
+	l.entries[index] = entry
+	l.now++
+	entry.refs = l.push(entry.refs)
+	heap.Push(&l.queue, entry)
+}
+
+// AfterRefresh is called after a new data packet refreshes an existing entry in the Content Store.
+func (l *CsLRUK) AfterRefresh(index uint64, wire []byte, data *defn.FwData) {
+	l.reference(index)
+}
+
+// BeforeErase is called before an entry is erased from the Content Store through management.
+func (l *CsLRUK) BeforeErase(index uint64, wire []byte) {
+	if entry, ok := l.entries[index]; ok {
+		heap.Remove(&l.queue, entry.pos)
+		delete(l.entries, index)
+	}
+}
+
+// BeforeUse is called before an entry in the Content Store is used to satisfy a pending Interest.
+func (l *CsLRUK) BeforeUse(index uint64, wire []byte) {
+	l.reference(index)
+}
+
+// EvictEntries is called to instruct the policy to evict enough entries to reduce
+// the Content Store size below its size limit.
+func (l *CsLRUK) EvictEntries() {
+	for csOverCapacity(l.cs, len(l.entries)) {
+		entry := heap.Pop(&l.queue).(*lrukEntry)
+		delete(l.entries, entry.index)
+		l.history.put(entry.index, entry.refs)
+		l.cs.eraseCsDataFromReplacementStrategy(entry.index)
+	}
+}
+
+func (l *CsLRUK) reference(index uint64) {
+	entry, ok := l.entries[index]
+	if !ok {
+		return
+	}
+	l.now++
+	entry.refs = l.push(entry.refs)
+	heap.Fix(&l.queue, entry.pos)
+}
+
+// push records a reference at the current time, keeping at most K references.
+func (l *CsLRUK) push(refs []uint64) []uint64 {
+	if len(refs) < l.k {
+		refs = append(refs, 0)
+	}
+	copy(refs[1:], refs)
+	refs[0] = l.now
+	return refs
+}
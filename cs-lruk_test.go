@@ -0,0 +1,33 @@
+package table
+
+import (
+	"slices"
+	"testing"
+)
+
+// TestCsLRUKEvictsOldestKthReference checks that entries referenced fewer than
+// K times are evicted first, then the entry whose K-th most recent reference is
+// the oldest, even if it was used more recently than the others.
+func TestCsLRUKEvictsOldestKthReference(t *testing.T) {
+	setTestCsCapacity(t, 2)
+	cs := &testCsTable{}
+	l := NewCsLRUK(cs, 2)
+
+	l.AfterInsert(0, nil, nil) // time 1
+	l.AfterInsert(1, nil, nil) // time 2
+	l.BeforeUse(1, nil)        // time 3
+	l.BeforeUse(0, nil)        // time 4: 0 is the most recently used, 2nd reference at 1
+
+	l.AfterInsert(2, nil, nil) // time 5
+	l.EvictEntries()
+	if !slices.Equal(cs.erased, []uint64{2}) {
+		t.Fatalf("evicted %v, want 2, which was referenced once", cs.erased)
+	}
+
+	l.AfterInsert(3, nil, nil) // time 6
+	l.BeforeUse(3, nil)        // time 7
+	l.EvictEntries()
+	if !slices.Equal(cs.erased, []uint64{2, 0}) {
+		t.Errorf("evicted %v, want 0, whose 2nd most recent reference is the oldest", cs.erased)
+	}
+}
@@ -0,0 +1,173 @@
+package table
+
+import (
+	"container/list"
+	"errors"
+	"math/bits"
+	"strconv"
+
+	"github.com/named-data/ndnd/fw/defn"
+)
+
+// CsMQ is a Multi-Queue (MQ) replacement policy for the Content Store.
+// Entries are kept in several LRU queues, queue i holding entries referenced
+// about 2^i times. An entry that is not referenced for lifeTime references
+// to the Content Store expires and drops to the next lower queue, so entries
+// that were popular a long time ago eventually become eviction candidates.
+// Victims are taken from the lowest non-empty queue, and the reference count
+// of evicted entries is remembered in the ghost queue Qout.
+type CsMQ struct {
+	cs       PitCsTable
+	lifeTime uint64
+	now      uint64
+	queues   []*list.List // of *mqEntry, least recently used first
+	entries  map[uint64]*mqEntry
+	out      *csGhost[int]
+}
+
+type mqEntry struct {
+	index  uint64
+	freq   int
+	expire uint64
+	queue  int
+	elem   *list.Element
+}
+
+// mqParams are the parameters accepted in the "mq" section of the
+// Content Store configuration, for example:
+//
+//	mq:
+//	  queues: 8       # number of LRU queues
+//	  life_time: 0    # references before an unused entry expires, 0 for the CS capacity
+var mqParams = []CsPolicyParam{
+	{Name: "queues", Default: 8},
+	{Name: "life_time", Default: 0},
+}
+
+func init() {
+	RegisterCsPolicy("mq", mqParams, func(params CsPolicyParams) error {
+		return errors.Join(
+			checkCsPolicyMin("queues", float64(params.Int("queues")), 1),
+			checkCsPolicyMin("life_time", float64(params.Int("life_time")), 0),
+		)
+	}, func(cs PitCsTable, params CsPolicyParams) (CsReplacementPolicy, error) {
+		return NewCsMQ(cs, params.Int("queues"), params.Int("life_time")), nil
+	})
+}
+
+// NewCsMQ creates a new MQ replacement policy for the Content Store with the
+// given number of queues. Entries expire after lifeTime references without
+// being used; a lifeTime of 0 or less uses the capacity.
+func NewCsMQ(cs PitCsTable, queues int, lifeTime int) *CsMQ {
+	if lifeTime <= 0 {
+		lifeTime = csCapacity()
+	}
+	m := new(CsMQ)
+	m.cs = cs
+	m.lifeTime = uint64(max(lifeTime, 1))
+	m.queues = make([]*list.List, max(queues, 1))
+	for i := range m.queues {
+		m.queues[i] = list.New()
+	}
+	m.entries = make(map[uint64]*mqEntry)
+	m.out = newCsGhost[int](csCapacity(), CsGhostLRU)
+	return m
+}
+
+// Stats returns the size of each queue.
+func (m *CsMQ) Stats() map[string]float64 {
+	stats := make(map[string]float64, len(m.queues)+1)
+	for i, queue := range m.queues {
+		stats["q"+strconv.Itoa(i)] = float64(queue.Len())
+	}
+	stats["out"] = float64(m.out.Len())
+	return stats
+}
+
+// AfterInsert is called after a new entry is inserted into the Content Store.
+func (m *CsMQ) AfterInsert(index uint64, wire []byte, data *defn.FwData) {
+	if _, ok := m.entries[index]; ok {
+		m.reference(index)
+		return
+	}
+	entry := &mqEntry{index: index}
+	if freq, ok := m.out.get(index); ok {
+		entry.freq = freq
+		m.out.remove(index)
+	}
+	m.entries[index] = entry
+	m.access(entry)
+}
+
+// AfterRefresh is called after a new data packet refreshes an existing entry in the Content Store.
+func (m *CsMQ) AfterRefresh(index uint64, wire []byte, data *defn.FwData) {
+	m.reference(index)
+}
+
+// BeforeErase is called before an entry is erased from the Content Store through management.
+func (m *CsMQ) BeforeErase(index uint64, wire []byte) {
+	if entry, ok := m.entries[index]; ok {
+		m.queues[entry.queue].Remove(entry.elem)
+		delete(m.entries, index)
+	}
+}
+
+// BeforeUse is called before an entry in the Content Store is used to satisfy a pending Interest.
+func (m *CsMQ) BeforeUse(index uint64, wire []byte) {
+	m.reference(index)
+}
+
+// EvictEntries is called to instruct the policy to evict enough entries to reduce
+// the Content Store size below its size limit.
+func (m *CsMQ) EvictEntries() {
+	for csOverCapacity(m.cs, len(m.entries)) {
+		for _, queue := range m.queues {
+			if queue.Len() == 0 {
+				continue
+			}
+			entry := queue.Remove(queue.Front()).(*mqEntry)
+			delete(m.entries, entry.index)
+			m.out.put(entry.index, entry.freq)
+			m.cs.eraseCsDataFromReplacementStrategy(entry.index)
+			break
+		}
+	}
+}
+
+func (m *CsMQ) reference(index uint64) {
+	if entry, ok := m.entries[index]; ok {
+		m.queues[entry.queue].Remove(entry.elem)
+		m.access(entry)
+	}
+}
+
+// access counts a reference to an entry that is in no queue, puts it at the
+// most recently used end of the queue for its frequency, then expires the
+// least recently used entry of every higher queue if its lifetime is over.
+func (m *CsMQ) access(entry *mqEntry) {
+	m.now++
+	entry.freq++
+	entry.expire = m.now + m.lifeTime
+	entry.queue = m.queueOf(entry.freq)
+	entry.elem = m.queues[entry.queue].PushBack(entry)
+
+	for i := 1; i < len(m.queues); i++ {
+		front := m.queues[i].Front()
+		if front == nil {
+			continue
+		}
+		expired := front.Value.(*mqEntry)
+		if expired.expire >= m.now {
+			continue
+		}
+		m.queues[i].Remove(front)
+		expired.queue = i - 1
+		expired.expire = m.now + m.lifeTime
+		expired.elem = m.queues[i-1].PushBack(expired)
+	}
+}
+
+// queueOf returns the queue for the given reference count, floor(log2(freq)).
+func (m *CsMQ) queueOf(freq int) int {
+	return min(bits.Len(uint(freq))-1, len(m.queues)-1)
+}
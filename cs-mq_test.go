@@ -0,0 +1,43 @@
+package table
+
+import (
+	"slices"
+	"testing"
+)
+
+// TestCsMQPromotesAndExpires checks that an entry moves to queue
+// floor(log2(freq)) as it is referenced, that it drops one queue when it is
+// not referenced for its lifetime, and that victims come from the lowest queue.
+func TestCsMQPromotesAndExpires(t *testing.T) {
+	setTestCsCapacity(t, 4)
+	cs := &testCsTable{}
+	m := NewCsMQ(cs, 4, 3)
+
+	m.AfterInsert(0, nil, nil)
+	for _, want := range []int{1, 1, 2} {
+		m.BeforeUse(0, nil)
+		if m.entries[0].queue != want {
+			t.Fatalf("entry with %d references is in queue %d, want %d", m.entries[0].freq, m.entries[0].queue, want)
+		}
+	}
+
+	// Every reference to the Content Store advances the time, so 0 expires
+	// after more than 3 references to other entries.
+	for index := uint64(1); index <= 4; index++ {
+		m.AfterInsert(index, nil, nil)
+		if index < 4 && m.entries[0].queue != 2 {
+			t.Fatalf("entry expired after %d references", index)
+		}
+	}
+	if m.entries[0].queue != 1 {
+		t.Errorf("entry not referenced for its lifetime is in queue %d, want 1", m.entries[0].queue)
+	}
+
+	m.EvictEntries()
+	if !slices.Equal(cs.erased, []uint64{1}) {
+		t.Errorf("evicted %v, want the least recently used entry of queue 0", cs.erased)
+	}
+	if freq, ok := m.out.get(1); !ok || freq != 1 {
+		t.Errorf("Qout remembers %d references of the victim, want 1", freq)
+	}
+}
@@ -0,0 +1,70 @@
+package table
+
+import (
+	"math/rand"
+
+	"github.com/named-data/ndnd/fw/defn"
+)
+
+// CsRandom is a random replacement policy for the Content Store.
+// It evicts an entry chosen uniformly at random. With a table implementing
+// csEntrySampler it keeps no per-entry state at all, which makes it a cheap
+// baseline for very large capacities.
+type CsRandom struct {
+	cs      PitCsTable
+	rng     *rand.Rand
+	entries csSampledEntries
+}
+
+func init() {
+	RegisterCsPolicy("random", []CsPolicyParam{
+		{Name: "seed", Default: 1},
+	}, nil, func(cs PitCsTable, params CsPolicyParams) (CsReplacementPolicy, error) {
+		return NewCsRandom(cs, int64(params.Int("seed"))), nil
+	})
+}
+
+// NewCsRandom creates a new random replacement policy for the Content Store,
+// picking victims with an RNG seeded with seed.
+func NewCsRandom(cs PitCsTable, seed int64) *CsRandom {
+	r := new(CsRandom)
+	r.cs = cs
+	r.rng = rand.New(rand.NewSource(seed))
+	r.entries = newCsSampledEntries(cs)
+	return r
+}
+
+// AfterInsert is called after a new entry is inserted into the Content Store.
+func (r *CsRandom) AfterInsert(index uint64, wire []byte, data *defn.FwData) {
+	r.entries.add(index)
+}
+
+// AfterRefresh is called after a new data packet refreshes an existing entry in the Content Store.
+func (r *CsRandom) AfterRefresh(index uint64, wire []byte, data *defn.FwData) {
+	// Nothing to do
+}
+
+// BeforeErase is called before an entry is erased from the Content Store through management.
+func (r *CsRandom) BeforeErase(index uint64, wire []byte) {
+	if r.entries.csPolicyWord(index) != nil {
+		r.entries.remove(index)
+	}
+}
+
+// BeforeUse is called before an entry in the Content Store is used to satisfy a pending Interest.
+func (r *CsRandom) BeforeUse(index uint64, wire []byte) {
+	// Nothing to do
+}
+
+// EvictEntries is called to instruct the policy to evict enough entries to reduce
+// the Content Store size below its size limit.
+func (r *CsRandom) EvictEntries() {
+	for csOverCapacity(r.cs, r.entries.count) {
+		indexToErase, _, ok := r.entries.sampleCsEntry(r.rng)
+		if !ok {
+			return
+		}
+		r.entries.remove(indexToErase)
+		r.cs.eraseCsDataFromReplacementStrategy(indexToErase)
+	}
+}
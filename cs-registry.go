@@ -0,0 +1,228 @@
+package table
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+)
+
+// CsPolicyFactory creates a new instance of a replacement policy for the given table.
+// The parameters have already been resolved against the policy's declaration
+// and accepted by its CsPolicyValidator.
+type CsPolicyFactory func(cs PitCsTable, params CsPolicyParams) (CsReplacementPolicy, error)
+
+// CsPolicyValidator checks the resolved parameters of a replacement policy,
+// such as value ranges, that the declaration alone cannot express.
+type CsPolicyValidator func(params CsPolicyParams) error
+
+// CsPolicyParam declares a configuration parameter accepted by a replacement policy.
+// The type of Default (float64, int, bool or string) is the type of the parameter.
+// If Choices is set, a string parameter must be one of the listed values.
+type CsPolicyParam struct {
+	Name    string
+	Default any
+	Choices []string
+}
+
+// CsPolicyParams holds the resolved parameters passed to a CsPolicyFactory.
+type CsPolicyParams map[string]any
+
+// Float returns the value of a float64 parameter.
+func (p CsPolicyParams) Float(name string) float64 {
+	v, _ := p[name].(float64)
+	return v
+}
+
+// Int returns the value of an int parameter.
+func (p CsPolicyParams) Int(name string) int {
+	v, _ := p[name].(int)
+	return v
+}
+
+// Bool returns the value of a bool parameter.
+func (p CsPolicyParams) Bool(name string) bool {
+	v, _ := p[name].(bool)
+	return v
+}
+
+// String returns the value of a string parameter.
+func (p CsPolicyParams) String(name string) string {
+	v, _ := p[name].(string)
+	return v
+}
+
+type csPolicyEntry struct {
+	params   []CsPolicyParam
+	validate CsPolicyValidator
+	factory  CsPolicyFactory
+}
+
+// csPolicies maps a policy name to its declaration and factory.
+var csPolicies = make(map[string]csPolicyEntry)
+
+// csPolicyConfig holds the per-policy parameters loaded from configuration.
+var csPolicyConfig = make(map[string]map[string]any)
+
+// RegisterCsPolicy registers a replacement policy under the given name.
+// validate may be nil if the declaration of params is enough to check them.
+// It is meant to be called from init() and panics if the name is already taken.
+func RegisterCsPolicy(name string, params []CsPolicyParam, validate CsPolicyValidator, factory CsPolicyFactory) {
+	if _, ok := csPolicies[name]; ok {
+		panic("duplicate CS replacement policy " + name)
+	}
+	for _, param := range params {
+		switch param.Default.(type) {
+		case float64, int, bool, string:
+		default:
+			panic(fmt.Sprintf("CS replacement policy %s: parameter %s has unsupported type %T",
+				name, param.Name, param.Default))
+		}
+	}
+	csPolicies[name] = csPolicyEntry{params: params, validate: validate, factory: factory}
+}
+
+// CsPolicyNames returns the sorted names of all registered replacement policies.
+func CsPolicyNames() []string {
+	names := make([]string, 0, len(csPolicies))
+	for name := range csPolicies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ValidateCsPolicy checks that the named policy is registered and that the
+// given parameters match its declaration and pass its validator.
+// The configuration loader should call it for the selected policy before
+// storing the parameters with SetCfgCsPolicyParams, so that errors are
+// reported while loading instead of when NewPitCS creates the policy.
+func ValidateCsPolicy(name string, raw map[string]any) error {
+	_, err := resolveCsPolicy(name, raw)
+	return err
+}
+
+// NewCsPolicy creates an instance of the named replacement policy for the given table.
+func NewCsPolicy(name string, cs PitCsTable, raw map[string]any) (CsReplacementPolicy, error) {
+	params, err := resolveCsPolicy(name, raw)
+	if err != nil {
+		return nil, err
+	}
+	return csPolicies[name].factory(cs, params)
+}
+
+// SetCfgCsPolicyParams sets the configured parameters of the named replacement policy.
+// They are not checked here; see ValidateCsPolicy.
+func SetCfgCsPolicyParams(name string, params map[string]any) {
+	csPolicyConfig[name] = params
+}
+
+// CfgCsPolicyParams returns the configured parameters of the named replacement policy.
+func CfgCsPolicyParams(name string) map[string]any {
+	return csPolicyConfig[name]
+}
+
+// resolveCsPolicy resolves the parameters of the named policy and validates them.
+func resolveCsPolicy(name string, raw map[string]any) (CsPolicyParams, error) {
+	entry, ok := csPolicies[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown CS replacement policy %q", name)
+	}
+	params, err := resolveCsPolicyParams(entry.params, raw)
+	if err != nil {
+		return nil, fmt.Errorf("CS replacement policy %s: %w", name, err)
+	}
+	if entry.validate != nil {
+		if err := entry.validate(params); err != nil {
+			return nil, fmt.Errorf("CS replacement policy %s: %w", name, err)
+		}
+	}
+	return params, nil
+}
+
+// resolveCsPolicyParams applies defaults and converts raw configuration values
+// to the types declared by the policy.
+func resolveCsPolicyParams(decl []CsPolicyParam, raw map[string]any) (CsPolicyParams, error) {
+	params := make(CsPolicyParams, len(decl))
+	choices := make(map[string][]string)
+	for _, param := range decl {
+		params[param.Name] = param.Default
+		if len(param.Choices) > 0 {
+			choices[param.Name] = param.Choices
+		}
+	}
+
+	for name, value := range raw {
+		def, ok := params[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown parameter %q", name)
+		}
+		converted, ok := convertCsPolicyParam(def, value)
+		if !ok {
+			return nil, fmt.Errorf("parameter %q: expected %T, got %T", name, def, value)
+		}
+		if allowed, ok := choices[name]; ok && !slices.Contains(allowed, converted.(string)) {
+			return nil, fmt.Errorf("parameter %q: %q is not one of %v", name, converted, allowed)
+		}
+		params[name] = converted
+	}
+	return params, nil
+}
+
+// convertCsPolicyParam converts a raw value to the type of def.
+// Configuration decoders may produce any numeric type, so numbers are converted freely
+// as long as no precision is lost when the target is an int.
+func convertCsPolicyParam(def any, value any) (any, bool) {
+	switch def.(type) {
+	case float64:
+		switch v := value.(type) {
+		case float64:
+			return v, true
+		case float32:
+			return float64(v), true
+		case int:
+			return float64(v), true
+		case int64:
+			return float64(v), true
+		case uint64:
+			return float64(v), true
+		}
+	case int:
+		switch v := value.(type) {
+		case int:
+			return v, true
+		case int64:
+			return int(v), true
+		case uint64:
+			return int(v), true
+		case float64:
+			if v == math.Trunc(v) {
+				return int(v), true
+			}
+		}
+	case bool:
+		v, ok := value.(bool)
+		return v, ok
+	case string:
+		v, ok := value.(string)
+		return v, ok
+	}
+	return nil, false
+}
+
+// checkCsPolicyRange returns an error if the value of the named parameter is
+// outside [lo, hi]. Validators use it for the common case of closed ranges.
+func checkCsPolicyRange(name string, value, lo, hi float64) error {
+	if !(value >= lo && value <= hi) {
+		return fmt.Errorf("parameter %q: %v is not in [%v, %v]", name, value, lo, hi)
+	}
+	return nil
+}
+
+// checkCsPolicyMin returns an error if the value of the named parameter is below lo.
+func checkCsPolicyMin(name string, value, lo float64) error {
+	if !(value >= lo) {
+		return fmt.Errorf("parameter %q: %v is less than %v", name, value, lo)
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package table
+
+import "testing"
+
+func TestValidateCsPolicyRanges(t *testing.T) {
+	valid := []struct {
+		policy string
+		raw    map[string]any
+	}{
+		{"lrfu", nil},
+		{"lrfu", map[string]any{"lambda": 0.2, "base": 0.25, "decay": LrfuDecayTime, "half_life": 30.0}},
+		{"lrfu", map[string]any{"half_life": -5.0}}, // only used with time decay
+		{"alrfu", nil},
+	}
+	for _, tt := range valid {
+		if err := ValidateCsPolicy(tt.policy, tt.raw); err != nil {
+			t.Errorf("ValidateCsPolicy(%q, %v) = %v, want nil", tt.policy, tt.raw, err)
+		}
+	}
+
+	invalid := []struct {
+		policy string
+		raw    map[string]any
+	}{
+		{"lrfu", map[string]any{"lambda": 7.0}},
+		{"lrfu", map[string]any{"lambda": -0.1}},
+		{"lrfu", map[string]any{"base": 0.0}},
+		{"lrfu", map[string]any{"base": 1.0}},
+		{"lrfu", map[string]any{"exponent": 0.0}},
+		{"lrfu", map[string]any{"decay": LrfuDecayTime, "half_life": -5.0}},
+		{"lrfu", map[string]any{"decay": LrfuDecayTime, "lambda": 0.0}}, // never halves
+		{"alrfu", map[string]any{"base": 2.0}},
+		{"alrfu", map[string]any{"step": 0.0}},
+		{"alrfu", map[string]any{"interval": 0}},
+	}
+	for _, tt := range invalid {
+		if err := ValidateCsPolicy(tt.policy, tt.raw); err == nil {
+			t.Errorf("ValidateCsPolicy(%q, %v) = nil, want an error", tt.policy, tt.raw)
+		}
+	}
+}
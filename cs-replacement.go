@@ -26,7 +26,13 @@ type CsReplacementPolicy interface {
 
 	// EvictEntries is called to instruct the policy to evict enough entries to reduce
 	// the Content Store size below its size limit.
+	// Both the entry limit and, if set, the byte limit apply; see csOverCapacity.
 	EvictEntries()
+}
 
-	getMinFrequency() int
+// CsPolicyStats is implemented by replacement policies that expose
+// internal state, such as self-tuned parameters, for observation.
+type CsPolicyStats interface {
+	// Stats returns the current value of each exposed variable by name.
+	Stats() map[string]float64
 }
@@ -0,0 +1,59 @@
+package table
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// testCsTable is a table for testing replacement policies on their own.
+// It records the entries that a policy erases; calling any other method of
+// PitCsTable panics.
+type testCsTable struct {
+	PitCsTable
+	erased []uint64
+}
+
+func (t *testCsTable) eraseCsDataFromReplacementStrategy(index uint64) {
+	t.erased = append(t.erased, index)
+}
+
+// setTestCsCapacity sets the entry limit of the Content Store until the end of the test.
+func setTestCsCapacity(tb interface{ Cleanup(func()) }, capacity int) {
+	old := csEntryLimit
+	csEntryLimit = capacity
+	tb.Cleanup(func() { csEntryLimit = old })
+}
+
+// setTestCsCapacityBytes sets the byte limit of the Content Store until the end of the test.
+func setTestCsCapacityBytes(tb interface{ Cleanup(func()) }, bytes int) {
+	old := CfgCsCapacityBytes()
+	SetCfgCsCapacityBytes(bytes)
+	tb.Cleanup(func() { SetCfgCsCapacityBytes(old) })
+}
+
+// BenchmarkBeforeUse measures the cost of a cache hit for policies holding
+// 1<<16 entries, each hit going to an entry chosen at random.
+func BenchmarkBeforeUse(b *testing.B) {
+	const entries = 1 << 16
+	for _, name := range []string{"clock", "clock-pro", "lru", "lfu", "lrfu"} {
+		b.Run(name, func(b *testing.B) {
+			setTestCsCapacity(b, entries)
+			policy, err := NewCsPolicy(name, &testCsTable{}, nil)
+			if err != nil {
+				b.Fatal(err)
+			}
+			for i := range entries {
+				policy.AfterInsert(uint64(i), nil, nil)
+			}
+			rng := rand.New(rand.NewSource(1))
+			indices := make([]uint64, 4096)
+			for i := range indices {
+				indices[i] = uint64(rng.Intn(entries))
+			}
+			b.ResetTimer()
+			for i := range b.N {
+				policy.BeforeUse(indices[i%len(indices)], nil)
+			}
+		})
+	}
+}
@@ -0,0 +1,163 @@
+package table
+
+import (
+	"container/list"
+	"math"
+
+	"github.com/named-data/ndnd/fw/defn"
+)
+
+// CsS3FIFO is an S3-FIFO replacement policy for the Content Store.
+// New entries enter the small FIFO queue S, which holds a small share of the
+// capacity. An entry leaving S moves to the main FIFO queue M if it was used
+// while in S, and is otherwise evicted and remembered in the ghost queue G.
+// An entry inserted again while in G goes straight to M. Entries leaving M
+// are reinserted while their access counter is non-zero, decrementing it.
+// Hits only increment a small counter, so no queue is ever reordered on use.
+type CsS3FIFO struct {
+	cs           PitCsTable
+	smallPercent float64
+	small        *list.List // S, of uint64, oldest first
+	main         *list.List // M, of uint64, oldest first
+	entries      map[uint64]*s3fifoEntry
+	ghost        *csGhost[struct{}]
+}
+
+type s3fifoEntry struct {
+	elem   *list.Element
+	freq   uint8
+	inMain bool
+}
+
+// s3fifoMaxFreq caps the access counter of an entry.
+const s3fifoMaxFreq = 3
+
+func init() {
+	RegisterCsPolicy("s3fifo", []CsPolicyParam{
+		{Name: "small_percent", Default: 10.0},
+	}, func(params CsPolicyParams) error {
+		return checkCsPolicyRange("small_percent", params.Float("small_percent"), 0.0, 100.0)
+	}, func(cs PitCsTable, params CsPolicyParams) (CsReplacementPolicy, error) {
+		return NewCsS3FIFO(cs, params.Float("small_percent")), nil
+	})
+}
+
+// NewCsS3FIFO creates a new S3-FIFO replacement policy for the Content Store,
+// reserving smallPercent of the capacity for the small queue.
+func NewCsS3FIFO(cs PitCsTable, smallPercent float64) *CsS3FIFO {
+	if smallPercent < 0.0 {
+		smallPercent = 0.0
+	} else if smallPercent > 100.0 {
+		smallPercent = 100.0
+	}
+	s := new(CsS3FIFO)
+	s.cs = cs
+	s.smallPercent = smallPercent
+	s.small = list.New()
+	s.main = list.New()
+	s.entries = make(map[uint64]*s3fifoEntry)
+	s.ghost = newCsGhost[struct{}](csCapacity()-s.smallCapacity(), CsGhostLRU)
+	return s
+}
+
+// smallCapacity returns the target size of the small queue.
+func (s *CsS3FIFO) smallCapacity() int {
+	return max(int(math.Ceil(float64(csCapacity())*s.smallPercent/100.0)), 1)
+}
+
+// Stats returns the size of each queue.
+func (s *CsS3FIFO) Stats() map[string]float64 {
+	return map[string]float64{
+		"small": float64(s.small.Len()),
+		"main":  float64(s.main.Len()),
+		"ghost": float64(s.ghost.Len()),
+	}
+}
+
+// AfterInsert is called after a new entry is inserted into the Content Store.
+func (s *CsS3FIFO) AfterInsert(index uint64, wire []byte, data *defn.FwData) {
+	if _, ok := s.entries[index]; ok {
+		s.use(index)
+		return
+	}
+	if s.ghost.contains(index) {
+		s.ghost.remove(index)
+		s.entries[index] = &s3fifoEntry{elem: s.main.PushBack(index), inMain: true}
+	} else {
+		s.entries[index] = &s3fifoEntry{elem: s.small.PushBack(index)}
+	}
+}
+
+// AfterRefresh is called after a new data packet refreshes an existing entry in the Content Store.
+func (s *CsS3FIFO) AfterRefresh(index uint64, wire []byte, data *defn.FwData) {
+	s.use(index)
+}
+
+// BeforeErase is called before an entry is erased from the Content Store through management.
+func (s *CsS3FIFO) BeforeErase(index uint64, wire []byte) {
+	entry, ok := s.entries[index]
+	if !ok {
+		return
+	}
+	if entry.inMain {
+		s.main.Remove(entry.elem)
+	} else {
+		s.small.Remove(entry.elem)
+	}
+	delete(s.entries, index)
+}
+
+// BeforeUse is called before an entry in the Content Store is used to satisfy a pending Interest.
+func (s *CsS3FIFO) BeforeUse(index uint64, wire []byte) {
+	s.use(index)
+}
+
+// EvictEntries is called to instruct the policy to evict enough entries to reduce
+// the Content Store size below its size limit.
+func (s *CsS3FIFO) EvictEntries() {
+	for csOverCapacity(s.cs, len(s.entries)) {
+		if s.small.Len() > 0 && (s.small.Len() >= s.smallCapacity() || s.main.Len() == 0) {
+			s.evictSmall()
+		} else {
+			s.evictMain()
+		}
+	}
+}
+
+// evictSmall takes the oldest entry of S, moving it to M if it was used since
+// its insertion and evicting it into G otherwise.
+func (s *CsS3FIFO) evictSmall() {
+	index := s.small.Remove(s.small.Front()).(uint64)
+	entry := s.entries[index]
+	if entry.freq > 0 {
+		entry.freq = 0
+		entry.inMain = true
+		entry.elem = s.main.PushBack(index)
+		return
+	}
+	delete(s.entries, index)
+	s.ghost.put(index, struct{}{})
+	s.cs.eraseCsDataFromReplacementStrategy(index)
+}
+
+// evictMain takes the oldest entry of M, reinserting it if it was used since
+// it was last examined and evicting it otherwise.
+func (s *CsS3FIFO) evictMain() {
+	front := s.main.Front()
+	index := front.Value.(uint64)
+	entry := s.entries[index]
+	if entry.freq > 0 {
+		entry.freq--
+		s.main.MoveToBack(front)
+		return
+	}
+	s.main.Remove(front)
+	delete(s.entries, index)
+	s.cs.eraseCsDataFromReplacementStrategy(index)
+}
+
+func (s *CsS3FIFO) use(index uint64) {
+	if entry, ok := s.entries[index]; ok && entry.freq < s3fifoMaxFreq {
+		entry.freq++
+	}
+}
@@ -0,0 +1,37 @@
+package table
+
+import (
+	"slices"
+	"testing"
+)
+
+// TestCsS3FIFOReadmitsGhostsToMain checks that an entry inserted again while in
+// the ghost queue goes straight to M, where one-time entries passing through S
+// do not evict it.
+func TestCsS3FIFOReadmitsGhostsToMain(t *testing.T) {
+	setTestCsCapacity(t, 10)
+	cs := &testCsTable{}
+	s := NewCsS3FIFO(cs, 10.0)
+
+	for index := range uint64(11) {
+		s.AfterInsert(index, nil, nil)
+	}
+	s.EvictEntries()
+	if !slices.Equal(cs.erased, []uint64{0}) || !s.ghost.contains(0) {
+		t.Fatalf("evicted %v, want 0 into the ghost queue", cs.erased)
+	}
+
+	s.AfterInsert(0, nil, nil)
+	if entry := s.entries[0]; entry == nil || !entry.inMain || s.ghost.contains(0) {
+		t.Fatalf("entry found in the ghost queue was not readmitted to M")
+	}
+	s.EvictEntries()
+
+	for index := uint64(100); index < 120; index++ {
+		s.AfterInsert(index, nil, nil)
+		s.EvictEntries()
+	}
+	if slices.Contains(cs.erased[1:], 0) || s.entries[0] == nil {
+		t.Errorf("one-time entries evicted %v, want 0 to stay in M", cs.erased[1:])
+	}
+}
@@ -0,0 +1,63 @@
+package table
+
+import "math/rand"
+
+// csSampleSet is a set of indices supporting insertion, removal and uniform
+// random picks in constant time. Policies with sampled eviction use it instead
+// of keeping their entries ordered.
+type csSampleSet struct {
+	indices   []uint64
+	positions map[uint64]int
+	rng       *rand.Rand
+}
+
+func newCsSampleSet(seed int64) *csSampleSet {
+	return &csSampleSet{
+		positions: make(map[uint64]int),
+		rng:       rand.New(rand.NewSource(seed)),
+	}
+}
+
+// Len returns the number of indices in the set.
+func (s *csSampleSet) Len() int {
+	return len(s.indices)
+}
+
+func (s *csSampleSet) add(index uint64) {
+	if _, ok := s.positions[index]; ok {
+		return
+	}
+	s.positions[index] = len(s.indices)
+	s.indices = append(s.indices, index)
+}
+
+func (s *csSampleSet) remove(index uint64) {
+	pos, ok := s.positions[index]
+	if !ok {
+		return
+	}
+	last := len(s.indices) - 1
+	s.indices[pos] = s.indices[last]
+	s.positions[s.indices[pos]] = pos
+	s.indices = s.indices[:last]
+	delete(s.positions, index)
+}
+
+// pick returns an index chosen uniformly at random. The set must not be empty.
+func (s *csSampleSet) pick() uint64 {
+	return s.indices[s.rng.Intn(len(s.indices))]
+}
+
+// sample calls fn on n indices picked at random, with replacement, or on
+// every index if the set holds no more than n.
+func (s *csSampleSet) sample(n int, fn func(index uint64)) {
+	if n >= len(s.indices) {
+		for _, index := range s.indices {
+			fn(index)
+		}
+		return
+	}
+	for range n {
+		fn(s.pick())
+	}
+}
@@ -0,0 +1,286 @@
+package table
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/named-data/ndnd/fw/defn"
+)
+
+// csEntrySampler is implemented by tables that keep a 32-bit policy word in
+// each Content Store entry and can pick entries at random, so that sampled
+// policies need no per-entry structure of their own. PitCsTree implements it.
+type csEntrySampler interface {
+	// sampleCsEntry returns the index and policy word of an entry chosen
+	// uniformly at random, or false if the Content Store is empty.
+	sampleCsEntry(rng *rand.Rand) (uint64, *uint32, bool)
+	// csPolicyWord returns the policy word of the entry, or nil if there is none.
+	csPolicyWord(index uint64) *uint32
+}
+
+// csLocalSampler keeps the policy words of sampled policies whose table does
+// not implement csEntrySampler.
+type csLocalSampler struct {
+	set   *csSampleSet
+	words map[uint64]*uint32
+}
+
+func newCsLocalSampler() *csLocalSampler {
+	return &csLocalSampler{
+		set:   newCsSampleSet(0),
+		words: make(map[uint64]*uint32),
+	}
+}
+
+func (s *csLocalSampler) add(index uint64) {
+	s.words[index] = new(uint32)
+	s.set.add(index)
+}
+
+func (s *csLocalSampler) remove(index uint64) {
+	delete(s.words, index)
+	s.set.remove(index)
+}
+
+func (s *csLocalSampler) sampleCsEntry(rng *rand.Rand) (uint64, *uint32, bool) {
+	if s.set.Len() == 0 {
+		return 0, nil, false
+	}
+	index := s.set.indices[rng.Intn(s.set.Len())]
+	return index, s.words[index], true
+}
+
+func (s *csLocalSampler) csPolicyWord(index uint64) *uint32 {
+	return s.words[index]
+}
+
+// csSampledEntries picks the entries of a sampled policy from its table, or
+// from a csLocalSampler if the table cannot do it.
+type csSampledEntries struct {
+	csEntrySampler
+	local *csLocalSampler // nil if the table is used
+	count int
+}
+
+func newCsSampledEntries(cs PitCsTable) csSampledEntries {
+	if sampler, ok := cs.(csEntrySampler); ok {
+		return csSampledEntries{csEntrySampler: sampler}
+	}
+	local := newCsLocalSampler()
+	return csSampledEntries{csEntrySampler: local, local: local}
+}
+
+func (e *csSampledEntries) add(index uint64) {
+	if e.local != nil {
+		if e.local.csPolicyWord(index) != nil {
+			return
+		}
+		e.local.add(index)
+	}
+	e.count++
+}
+
+func (e *csSampledEntries) remove(index uint64) {
+	e.count--
+	if e.local != nil {
+		e.local.remove(index)
+	}
+}
+
+// Sampled eviction modes, selected by the policy name.
+const (
+	// CsSampledLRU evicts the sampled entry used least recently.
+	CsSampledLRU = "lru"
+	// CsSampledLFU evicts the sampled entry with the lowest decayed
+	// logarithmic use counter.
+	CsSampledLFU = "lfu"
+)
+
+// CsSampled is a Redis-style approximate LRU or LFU replacement policy for the
+// Content Store, meant for very large capacities. Instead of ordering entries,
+// it keeps a 32-bit word in each Content Store entry and evicts the worst of a
+// few entries picked at random.
+//
+// In LRU mode the word is the time of the last use, counted in references to
+// the Content Store. In LFU mode, the low 8 bits are a logarithmic use counter
+// and the high 16 bits the minute it was last decremented; the counter is
+// incremented with a probability falling as it grows, and decremented once for
+// every decayTime minutes in which the entry was not used.
+type CsSampled struct {
+	cs        PitCsTable
+	mode      string
+	samples   int
+	rng       *rand.Rand
+	entries   csSampledEntries
+	now       uint32
+	logFactor float64
+	decayTime uint32 // minutes
+}
+
+const (
+	sampledLfuInitial = 5 // counter of a new entry, so it is not evicted right away
+	sampledLfuMax     = 255
+)
+
+// sampledLruParams are the parameters accepted in the "sampled-lru" section of the
+// Content Store configuration, for example:
+//
+//	sampled-lru:
+//	  samples: 5   # entries compared for each eviction
+//	  seed: 1      # seed of the RNG picking the samples
+var sampledLruParams = []CsPolicyParam{
+	{Name: "samples", Default: 5},
+	{Name: "seed", Default: 1},
+}
+
+// sampledLfuParams are the parameters accepted in the "sampled-lfu" section of the
+// Content Store configuration, for example:
+//
+//	sampled-lfu:
+//	  samples: 5        # entries compared for each eviction
+//	  seed: 1           # seed of the RNG picking the samples and incrementing counters
+//	  log_factor: 10.0  # higher values need more uses to grow the counter
+//	  decay_time: 1     # minutes without use per counter decrement, 0 to disable
+var sampledLfuParams = []CsPolicyParam{
+	{Name: "samples", Default: 5},
+	{Name: "seed", Default: 1},
+	{Name: "log_factor", Default: 10.0},
+	{Name: "decay_time", Default: 1},
+}
+
+func init() {
+	RegisterCsPolicy("sampled-lru", sampledLruParams, func(params CsPolicyParams) error {
+		return checkCsPolicyMin("samples", float64(params.Int("samples")), 1)
+	}, func(cs PitCsTable, params CsPolicyParams) (CsReplacementPolicy, error) {
+		return NewCsSampled(cs, CsSampledLRU, params.Int("samples"), int64(params.Int("seed"))), nil
+	})
+	RegisterCsPolicy("sampled-lfu", sampledLfuParams, func(params CsPolicyParams) error {
+		return errors.Join(
+			checkCsPolicyMin("samples", float64(params.Int("samples")), 1),
+			checkCsPolicyMin("log_factor", params.Float("log_factor"), 0.0),
+			checkCsPolicyMin("decay_time", float64(params.Int("decay_time")), 0),
+		)
+	}, func(cs PitCsTable, params CsPolicyParams) (CsReplacementPolicy, error) {
+		s := NewCsSampled(cs, CsSampledLFU, params.Int("samples"), int64(params.Int("seed")))
+		s.setLfuDecay(params.Float("log_factor"), params.Int("decay_time"))
+		return s, nil
+	})
+}
+
+// NewCsSampled creates a new sampled replacement policy for the Content Store,
+// in CsSampledLRU or CsSampledLFU mode, comparing the given number of entries
+// on eviction.
+func NewCsSampled(cs PitCsTable, mode string, samples int, seed int64) *CsSampled {
+	s := new(CsSampled)
+	s.cs = cs
+	s.mode = mode
+	s.samples = max(samples, 1)
+	s.rng = rand.New(rand.NewSource(seed))
+	s.entries = newCsSampledEntries(cs)
+	s.logFactor = 10.0
+	s.decayTime = 1
+	return s
+}
+
+func (s *CsSampled) setLfuDecay(logFactor float64, decayTime int) {
+	s.logFactor = math.Max(logFactor, 0.0)
+	s.decayTime = uint32(max(decayTime, 0))
+}
+
+// AfterInsert is called after a new entry is inserted into the Content Store.
+func (s *CsSampled) AfterInsert(index uint64, wire []byte, data *defn.FwData) {
+	s.entries.add(index)
+	word := s.entries.csPolicyWord(index)
+	if word == nil {
+		return
+	}
+	if s.mode == CsSampledLFU {
+		*word = s.minutes()<<8 | sampledLfuInitial
+	} else {
+		s.now++
+		*word = s.now
+	}
+}
+
+// AfterRefresh is called after a new data packet refreshes an existing entry in the Content Store.
+func (s *CsSampled) AfterRefresh(index uint64, wire []byte, data *defn.FwData) {
+	s.BeforeUse(index, wire)
+}
+
+// BeforeErase is called before an entry is erased from the Content Store through management.
+func (s *CsSampled) BeforeErase(index uint64, wire []byte) {
+	if s.entries.csPolicyWord(index) != nil {
+		s.entries.remove(index)
+	}
+}
+
+// BeforeUse is called before an entry in the Content Store is used to satisfy a pending Interest.
+func (s *CsSampled) BeforeUse(index uint64, wire []byte) {
+	word := s.entries.csPolicyWord(index)
+	if word == nil {
+		return
+	}
+	if s.mode == CsSampledLFU {
+		counter := s.decayed(*word)
+		if counter < sampledLfuMax {
+			p := 1.0 / (float64(max(counter, sampledLfuInitial)-sampledLfuInitial)*s.logFactor + 1.0)
+			if s.rng.Float64() < p {
+				counter++
+			}
+		}
+		*word = s.minutes()<<8 | counter
+	} else {
+		s.now++
+		*word = s.now
+	}
+}
+
+// EvictEntries is called to instruct the policy to evict enough entries to reduce
+// the Content Store size below its size limit.
+func (s *CsSampled) EvictEntries() {
+	for csOverCapacity(s.cs, s.entries.count) {
+		var indexToErase uint64
+		var worst uint32
+		found := false
+		for range s.samples {
+			index, word, ok := s.entries.sampleCsEntry(s.rng)
+			if !ok {
+				break
+			}
+			if score := s.score(*word); !found || score > worst {
+				indexToErase, worst, found = index, score, true
+			}
+		}
+		if !found {
+			return
+		}
+		s.entries.remove(indexToErase)
+		s.cs.eraseCsDataFromReplacementStrategy(indexToErase)
+	}
+}
+
+// score returns how good a victim the entry with the given word is, higher is better.
+func (s *CsSampled) score(word uint32) uint32 {
+	if s.mode == CsSampledLFU {
+		return sampledLfuMax - s.decayed(word)
+	}
+	return s.now - word // idle time, correct across wraparound
+}
+
+// decayed returns the LFU counter of a word, decremented for the minutes elapsed
+// since it was last decremented.
+func (s *CsSampled) decayed(word uint32) uint32 {
+	counter := word & 0xff
+	if s.decayTime == 0 {
+		return counter
+	}
+	elapsed := (s.minutes() - word>>8) & 0xffff
+	return counter - min(counter, elapsed/s.decayTime)
+}
+
+// minutes returns the current time in minutes, wrapping at 16 bits.
+func (s *CsSampled) minutes() uint32 {
+	return uint32(time.Now().Unix()/60) & 0xffff
+}
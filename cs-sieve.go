@@ -0,0 +1,98 @@
+package table
+
+import (
+	"container/list"
+
+	"github.com/named-data/ndnd/fw/defn"
+)
+
+// CsSIEVE is a SIEVE replacement policy for the Content Store.
+// Entries are kept in a single queue in insertion order, each with a visited
+// bit set on use. The hand moves from the oldest entry towards the newest,
+// clearing visited bits as it passes, and evicts the first entry without one.
+// Unlike CLOCK, surviving entries keep their position instead of being moved
+// to the head, so new entries that are not reused are evicted quickly.
+type CsSIEVE struct {
+	cs      PitCsTable
+	queue   *list.List // of *sieveEntry, newest first
+	hand    *list.Element
+	entries map[uint64]*list.Element
+}
+
+type sieveEntry struct {
+	index   uint64
+	visited bool
+}
+
+func init() {
+	RegisterCsPolicy("sieve", nil, nil, func(cs PitCsTable, _ CsPolicyParams) (CsReplacementPolicy, error) {
+		return NewCsSIEVE(cs), nil
+	})
+}
+
+// NewCsSIEVE creates a new SIEVE replacement policy for the Content Store.
+func NewCsSIEVE(cs PitCsTable) *CsSIEVE {
+	s := new(CsSIEVE)
+	s.cs = cs
+	s.queue = list.New()
+	s.entries = make(map[uint64]*list.Element)
+	return s
+}
+
+// AfterInsert is called after a new entry is inserted into the Content Store.
+func (s *CsSIEVE) AfterInsert(index uint64, wire []byte, data *defn.FwData) {
+	if _, ok := s.entries[index]; ok {
+		s.BeforeUse(index, wire)
+		return
+	}
+	s.entries[index] = s.queue.PushFront(&sieveEntry{index: index})
+}
+
+// AfterRefresh is called after a new data packet refreshes an existing entry in the Content Store.
+func (s *CsSIEVE) AfterRefresh(index uint64, wire []byte, data *defn.FwData) {
+	s.BeforeUse(index, wire)
+}
+
+// BeforeErase is called before an entry is erased from the Content Store through management.
+func (s *CsSIEVE) BeforeErase(index uint64, wire []byte) {
+	if elem, ok := s.entries[index]; ok {
+		s.remove(elem)
+	}
+}
+
+// BeforeUse is called before an entry in the Content Store is used to satisfy a pending Interest.
+func (s *CsSIEVE) BeforeUse(index uint64, wire []byte) {
+	if elem, ok := s.entries[index]; ok {
+		elem.Value.(*sieveEntry).visited = true
+	}
+}
+
+// EvictEntries is called to instruct the policy to evict enough entries to reduce
+// the Content Store size below its size limit.
+func (s *CsSIEVE) EvictEntries() {
+	for csOverCapacity(s.cs, len(s.entries)) {
+		elem := s.hand
+		if elem == nil {
+			elem = s.queue.Back()
+		}
+		for elem.Value.(*sieveEntry).visited {
+			elem.Value.(*sieveEntry).visited = false
+			if elem = elem.Prev(); elem == nil {
+				elem = s.queue.Back()
+			}
+		}
+		indexToErase := elem.Value.(*sieveEntry).index
+		s.hand = elem
+		s.remove(elem)
+		s.cs.eraseCsDataFromReplacementStrategy(indexToErase)
+	}
+}
+
+// remove takes an entry out of the queue, moving the hand to the next newer entry if it was on it.
+func (s *CsSIEVE) remove(elem *list.Element) {
+	if s.hand == elem {
+		s.hand = elem.Prev()
+	}
+	delete(s.entries, elem.Value.(*sieveEntry).index)
+	s.queue.Remove(elem)
+}
@@ -0,0 +1,131 @@
+package table
+
+import "math/bits"
+
+// csSketch is a Count-Min Sketch of 4-bit counters estimating how often each
+// index was seen, in memory fixed by its width. A doorkeeper Bloom filter in
+// front of it absorbs the first occurrence of each index, so indices seen only
+// once never reach the counters. Once sampleSize occurrences were recorded,
+// every counter is halved and the doorkeeper is cleared, so that estimates
+// follow recent popularity.
+type csSketch struct {
+	rows       [csSketchDepth][]uint64 // 16 counters per word
+	mask       uint64                  // counters per row - 1
+	doorkeeper []uint64
+	doorMask   uint64 // doorkeeper bits - 1
+	samples    int
+	sampleSize int
+}
+
+const (
+	csSketchDepth    = 4
+	csSketchMaxCount = 15
+	csSketchHashes   = 2 // doorkeeper hash functions
+)
+
+// newCsSketch creates a sketch sized for a cache of the given capacity.
+func newCsSketch(capacity int) *csSketch {
+	capacity = max(capacity, 1)
+	s := new(csSketch)
+	width := csNextPow2(max(capacity, 16))
+	for i := range s.rows {
+		s.rows[i] = make([]uint64, width/16)
+	}
+	s.mask = uint64(width - 1)
+	s.sampleSize = 10 * capacity
+	doorBits := csNextPow2(4 * s.sampleSize)
+	s.doorkeeper = make([]uint64, max(doorBits/64, 1))
+	s.doorMask = uint64(len(s.doorkeeper)*64 - 1)
+	return s
+}
+
+// csNextPow2 returns the smallest power of two not less than n.
+func csNextPow2(n int) int {
+	if n <= 1 {
+		return 1
+	}
+	return 1 << bits.Len(uint(n-1))
+}
+
+// csHash mixes an index into two independent hashes (splitmix64 finalizer),
+// from which the positions in each row are derived by double hashing.
+func csHash(index uint64) (uint64, uint64) {
+	h := index + 0x9e3779b97f4a7c15
+	h = (h ^ (h >> 30)) * 0xbf58476d1ce4e5b9
+	h = (h ^ (h >> 27)) * 0x94d049bb133111eb
+	h ^= h >> 31
+	return h, (h>>32 | h<<32) | 1
+}
+
+// increment records an occurrence of the index.
+func (s *csSketch) increment(index uint64) {
+	h1, h2 := csHash(index)
+	if s.admitDoorkeeper(h1, h2) {
+		for i := range s.rows {
+			s.incrementAt(i, (h1+uint64(i)*h2)&s.mask)
+		}
+	}
+
+	s.samples++
+	if s.samples >= s.sampleSize {
+		s.reset()
+	}
+}
+
+// estimate returns the estimated number of recent occurrences of the index.
+func (s *csSketch) estimate(index uint64) int {
+	h1, h2 := csHash(index)
+	count := csSketchMaxCount
+	for i := range s.rows {
+		count = min(count, s.counterAt(i, (h1+uint64(i)*h2)&s.mask))
+	}
+	if s.inDoorkeeper(h1, h2) {
+		count++
+	}
+	return count
+}
+
+func (s *csSketch) counterAt(row int, pos uint64) int {
+	return int(s.rows[row][pos/16]>>((pos%16)*4)) & csSketchMaxCount
+}
+
+func (s *csSketch) incrementAt(row int, pos uint64) {
+	if s.counterAt(row, pos) < csSketchMaxCount {
+		s.rows[row][pos/16] += 1 << ((pos % 16) * 4)
+	}
+}
+
+// admitDoorkeeper sets the bits of the index in the doorkeeper, returning
+// whether they were all set already.
+func (s *csSketch) admitDoorkeeper(h1, h2 uint64) bool {
+	present := true
+	for i := uint64(0); i < csSketchHashes; i++ {
+		bit := (h2 + i*h1) & s.doorMask
+		if s.doorkeeper[bit/64]&(1<<(bit%64)) == 0 {
+			present = false
+			s.doorkeeper[bit/64] |= 1 << (bit % 64)
+		}
+	}
+	return present
+}
+
+func (s *csSketch) inDoorkeeper(h1, h2 uint64) bool {
+	for i := uint64(0); i < csSketchHashes; i++ {
+		bit := (h2 + i*h1) & s.doorMask
+		if s.doorkeeper[bit/64]&(1<<(bit%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// reset halves every counter and clears the doorkeeper.
+func (s *csSketch) reset() {
+	for i := range s.rows {
+		for j := range s.rows[i] {
+			s.rows[i][j] = (s.rows[i][j] >> 1) & 0x7777777777777777
+		}
+	}
+	clear(s.doorkeeper)
+	s.samples /= 2
+}
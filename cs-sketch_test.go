@@ -0,0 +1,47 @@
+package table
+
+import "testing"
+
+// TestCsSketchAgesCounters checks that the doorkeeper absorbs the first
+// occurrence of an index, and that after sampleSize occurrences every counter
+// is halved and the doorkeeper is cleared.
+func TestCsSketchAgesCounters(t *testing.T) {
+	s := newCsSketch(10) // sampleSize 100
+	s.increment(1)
+	if got := s.estimate(1); got != 1 {
+		t.Errorf("estimate is %d after one occurrence, want 1 from the doorkeeper", got)
+	}
+	for range 9 {
+		s.increment(1)
+	}
+	for range 89 {
+		s.increment(2)
+	}
+	if got := s.estimate(1); got != 10 {
+		t.Fatalf("estimate is %d after 10 occurrences, want 10", got)
+	}
+	if got := s.estimate(2); got != csSketchMaxCount+1 {
+		t.Fatalf("estimate is %d after 89 occurrences, want the saturated %d", got, csSketchMaxCount+1)
+	}
+
+	s.increment(2) // the 100th occurrence
+	if s.samples != 50 {
+		t.Errorf("%d samples after aging, want 50", s.samples)
+	}
+	if got := s.estimate(1); got != 4 {
+		t.Errorf("estimate is %d after aging, want the counter 9 halved to 4 without the doorkeeper", got)
+	}
+	if got := s.estimate(2); got != csSketchMaxCount/2 {
+		t.Errorf("estimate is %d after aging, want %d", got, csSketchMaxCount/2)
+	}
+
+	// The doorkeeper absorbs the next occurrence again, then the counters count.
+	s.increment(1)
+	if got := s.estimate(1); got != 5 {
+		t.Errorf("estimate is %d, want 5 from the doorkeeper", got)
+	}
+	s.increment(1)
+	if got := s.estimate(1); got != 6 {
+		t.Errorf("estimate is %d, want 6", got)
+	}
+}
@@ -0,0 +1,117 @@
+package table
+
+import (
+	"container/list"
+
+	"github.com/named-data/ndnd/fw/defn"
+)
+
+// CsSLRU is a Segmented LRU replacement policy for the Content Store.
+// New entries enter the probationary segment, and entries referenced again are
+// promoted to the protected segment. When the protected segment is full, its
+// least recently used entry goes back to the probationary segment, from which
+// all evictions are made.
+type CsSLRU struct {
+	cs             PitCsTable
+	protectedRatio float64
+	probationary   *list.List // of uint64, least recently used first
+	protected      *list.List // of uint64, least recently used first
+	locations      map[uint64]*list.Element
+	isProtected    map[uint64]bool
+}
+
+func init() {
+	RegisterCsPolicy("slru", []CsPolicyParam{
+		{Name: "protected_ratio", Default: 0.8},
+	}, func(params CsPolicyParams) error {
+		return checkCsPolicyRange("protected_ratio", params.Float("protected_ratio"), 0.0, 1.0)
+	}, func(cs PitCsTable, params CsPolicyParams) (CsReplacementPolicy, error) {
+		return NewCsSLRU(cs, params.Float("protected_ratio")), nil
+	})
+}
+
+// NewCsSLRU creates a new SLRU replacement policy for the Content Store,
+// with the given share of the capacity for the protected segment.
+func NewCsSLRU(cs PitCsTable, protectedRatio float64) *CsSLRU {
+	if protectedRatio < 0.0 {
+		protectedRatio = 0.0
+	} else if protectedRatio > 1.0 {
+		protectedRatio = 1.0
+	}
+	s := new(CsSLRU)
+	s.cs = cs
+	s.protectedRatio = protectedRatio
+	s.probationary = list.New()
+	s.protected = list.New()
+	s.locations = make(map[uint64]*list.Element)
+	s.isProtected = make(map[uint64]bool)
+	return s
+}
+
+// AfterInsert is called after a new entry is inserted into the Content Store.
+func (s *CsSLRU) AfterInsert(index uint64, wire []byte, data *defn.FwData) {
+	s.locations[index] = s.probationary.PushBack(index)
+}
+
+// AfterRefresh is called after a new data packet refreshes an existing entry in the Content Store.
+func (s *CsSLRU) AfterRefresh(index uint64, wire []byte, data *defn.FwData) {
+	s.use(index)
+}
+
+// BeforeErase is called before an entry is erased from the Content Store through management.
+func (s *CsSLRU) BeforeErase(index uint64, wire []byte) {
+	if location, ok := s.locations[index]; ok {
+		if s.isProtected[index] {
+			s.protected.Remove(location)
+		} else {
+			s.probationary.Remove(location)
+		}
+		delete(s.locations, index)
+		delete(s.isProtected, index)
+	}
+}
+
+// BeforeUse is called before an entry in the Content Store is used to satisfy a pending Interest.
+func (s *CsSLRU) BeforeUse(index uint64, wire []byte) {
+	s.use(index)
+}
+
+// EvictEntries is called to instruct the policy to evict enough entries to reduce
+// the Content Store size below its size limit.
+func (s *CsSLRU) EvictEntries() {
+	for csOverCapacity(s.cs, len(s.locations)) {
+		var indexToErase uint64
+		if s.probationary.Len() > 0 {
+			indexToErase = s.probationary.Remove(s.probationary.Front()).(uint64)
+		} else {
+			indexToErase = s.protected.Remove(s.protected.Front()).(uint64)
+			delete(s.isProtected, indexToErase)
+		}
+		delete(s.locations, indexToErase)
+		s.cs.eraseCsDataFromReplacementStrategy(indexToErase)
+	}
+}
+
+// use promotes the entry to the most recently used end of the protected segment,
+// demoting protected entries back to probation if the segment overflows.
+func (s *CsSLRU) use(index uint64) {
+	location, ok := s.locations[index]
+	if !ok {
+		return
+	}
+	if s.isProtected[index] {
+		s.protected.MoveToBack(location)
+		return
+	}
+
+	s.probationary.Remove(location)
+	s.locations[index] = s.protected.PushBack(index)
+	s.isProtected[index] = true
+
+	limit := int(float64(csCapacity()) * s.protectedRatio)
+	for s.protected.Len() > limit {
+		demoted := s.protected.Remove(s.protected.Front()).(uint64)
+		delete(s.isProtected, demoted)
+		s.locations[demoted] = s.probationary.PushBack(demoted)
+	}
+}
@@ -0,0 +1,221 @@
+package table
+
+import (
+	"container/list"
+	"errors"
+
+	"github.com/named-data/ndnd/fw/defn"
+)
+
+// CsWTinyLFU is a W-TinyLFU replacement policy for the Content Store.
+// New entries enter a small LRU window. An entry leaving the window becomes a
+// candidate for the main region, a Segmented LRU, and is only admitted if its
+// estimated frequency is higher than that of the main region's victim;
+// otherwise the candidate itself is evicted. Frequencies are estimated by a
+// csSketch, so the policy needs no per-index state beyond the resident entries.
+type CsWTinyLFU struct {
+	cs             PitCsTable
+	windowPercent  float64
+	protectedRatio float64
+	window         *list.List // of uint64, least recently used first
+	probationary   *list.List // of uint64, least recently used first
+	protected      *list.List // of uint64, least recently used first
+	entries        map[uint64]*wtinylfuEntry
+	sketch         *csSketch
+	admitted       int
+	rejected       int
+}
+
+type wtinylfuRegion int
+
+const (
+	wtinylfuWindow wtinylfuRegion = iota
+	wtinylfuProbationary
+	wtinylfuProtected
+)
+
+type wtinylfuEntry struct {
+	elem   *list.Element
+	region wtinylfuRegion
+}
+
+// wtinylfuParams are the parameters accepted in the "wtinylfu" section of the
+// Content Store configuration, for example:
+//
+//	wtinylfu:
+//	  window_percent: 1.0   # share of the capacity used by the window
+//	  protected_ratio: 0.8  # share of the main region used by the protected segment
+var wtinylfuParams = []CsPolicyParam{
+	{Name: "window_percent", Default: 1.0},
+	{Name: "protected_ratio", Default: 0.8},
+}
+
+func init() {
+	RegisterCsPolicy("wtinylfu", wtinylfuParams, func(params CsPolicyParams) error {
+		return errors.Join(
+			checkCsPolicyRange("window_percent", params.Float("window_percent"), 0.0, 100.0),
+			checkCsPolicyRange("protected_ratio", params.Float("protected_ratio"), 0.0, 1.0),
+		)
+	}, func(cs PitCsTable, params CsPolicyParams) (CsReplacementPolicy, error) {
+		return NewCsWTinyLFU(cs, params.Float("window_percent"), params.Float("protected_ratio")), nil
+	})
+}
+
+// NewCsWTinyLFU creates a new W-TinyLFU replacement policy for the Content Store.
+// windowPercent is the share of the capacity used by the window, and
+// protectedRatio the share of the main region used by its protected segment.
+func NewCsWTinyLFU(cs PitCsTable, windowPercent float64, protectedRatio float64) *CsWTinyLFU {
+	if windowPercent < 0.0 {
+		windowPercent = 0.0
+	} else if windowPercent > 100.0 {
+		windowPercent = 100.0
+	}
+	if protectedRatio < 0.0 {
+		protectedRatio = 0.0
+	} else if protectedRatio > 1.0 {
+		protectedRatio = 1.0
+	}
+	w := new(CsWTinyLFU)
+	w.cs = cs
+	w.windowPercent = windowPercent
+	w.protectedRatio = protectedRatio
+	w.window = list.New()
+	w.probationary = list.New()
+	w.protected = list.New()
+	w.entries = make(map[uint64]*wtinylfuEntry)
+	w.sketch = newCsSketch(csCapacity())
+	return w
+}
+
+// Stats returns the size of each region and the admission decisions made so far.
+func (w *CsWTinyLFU) Stats() map[string]float64 {
+	return map[string]float64{
+		"window":       float64(w.window.Len()),
+		"probationary": float64(w.probationary.Len()),
+		"protected":    float64(w.protected.Len()),
+		"admitted":     float64(w.admitted),
+		"rejected":     float64(w.rejected),
+	}
+}
+
+// windowCapacity returns the target size of the window, at least one entry.
+func (w *CsWTinyLFU) windowCapacity() int {
+	return max(int(float64(csCapacity())*w.windowPercent/100.0), 1)
+}
+
+// AfterInsert is called after a new entry is inserted into the Content Store.
+func (w *CsWTinyLFU) AfterInsert(index uint64, wire []byte, data *defn.FwData) {
+	if _, ok := w.entries[index]; ok {
+		w.use(index)
+		return
+	}
+	w.sketch.increment(index)
+	w.entries[index] = &wtinylfuEntry{elem: w.window.PushBack(index), region: wtinylfuWindow}
+}
+
+// AfterRefresh is called after a new data packet refreshes an existing entry in the Content Store.
+func (w *CsWTinyLFU) AfterRefresh(index uint64, wire []byte, data *defn.FwData) {
+	w.use(index)
+}
+
+// BeforeErase is called before an entry is erased from the Content Store through management.
+func (w *CsWTinyLFU) BeforeErase(index uint64, wire []byte) {
+	if entry, ok := w.entries[index]; ok {
+		w.region(entry.region).Remove(entry.elem)
+		delete(w.entries, index)
+	}
+}
+
+// BeforeUse is called before an entry in the Content Store is used to satisfy a pending Interest.
+func (w *CsWTinyLFU) BeforeUse(index uint64, wire []byte) {
+	w.use(index)
+}
+
+// EvictEntries is called to instruct the policy to evict enough entries to reduce
+// the Content Store size below its size limit.
+func (w *CsWTinyLFU) EvictEntries() {
+	mainCapacity := csCapacity() - w.windowCapacity()
+	for csOverCapacity(w.cs, len(w.entries)) {
+		mainLen := w.probationary.Len() + w.protected.Len()
+		if w.window.Len() <= w.windowCapacity() && mainLen > 0 {
+			w.evict(w.mainVictim())
+			continue
+		}
+
+		// The window is over its size: its least recently used entry is a candidate
+		candidate := w.window.Front().Value.(uint64)
+		if mainLen < mainCapacity {
+			w.move(candidate, wtinylfuProbationary)
+			continue
+		}
+		if mainLen == 0 {
+			w.evict(candidate)
+			continue
+		}
+		victim := w.mainVictim()
+		if w.sketch.estimate(candidate) > w.sketch.estimate(victim) {
+			w.admitted++
+			w.evict(victim)
+			w.move(candidate, wtinylfuProbationary)
+		} else {
+			w.rejected++
+			w.evict(candidate)
+		}
+	}
+}
+
+// use records a reference to a resident entry and updates its position.
+// An entry referenced in the probationary segment is promoted to the protected
+// segment, whose least recently used entry is demoted if it is full.
+func (w *CsWTinyLFU) use(index uint64) {
+	entry, ok := w.entries[index]
+	if !ok {
+		return
+	}
+	w.sketch.increment(index)
+	switch entry.region {
+	case wtinylfuWindow:
+		w.window.MoveToBack(entry.elem)
+	case wtinylfuProtected:
+		w.protected.MoveToBack(entry.elem)
+	case wtinylfuProbationary:
+		w.move(index, wtinylfuProtected)
+		protectedCapacity := int(float64(csCapacity()-w.windowCapacity()) * w.protectedRatio)
+		for w.protected.Len() > protectedCapacity {
+			w.move(w.protected.Front().Value.(uint64), wtinylfuProbationary)
+		}
+	}
+}
+
+// mainVictim returns the entry the main region would evict next.
+// The main region must not be empty.
+func (w *CsWTinyLFU) mainVictim() uint64 {
+	if w.probationary.Len() > 0 {
+		return w.probationary.Front().Value.(uint64)
+	}
+	return w.protected.Front().Value.(uint64)
+}
+
+func (w *CsWTinyLFU) region(region wtinylfuRegion) *list.List {
+	switch region {
+	case wtinylfuProbationary:
+		return w.probationary
+	case wtinylfuProtected:
+		return w.protected
+	default:
+		return w.window
+	}
+}
+
+// move puts an entry at the most recently used end of the given region.
+func (w *CsWTinyLFU) move(index uint64, region wtinylfuRegion) {
+	entry := w.entries[index]
+	w.region(entry.region).Remove(entry.elem)
+	entry.region = region
+	entry.elem = w.region(region).PushBack(index)
+}
+
+func (w *CsWTinyLFU) evict(index uint64) {
+	w.BeforeErase(index, nil)
+	w.cs.eraseCsDataFromReplacementStrategy(index)
+}
@@ -0,0 +1,45 @@
+package table
+
+import (
+	"slices"
+	"testing"
+)
+
+// TestCsWTinyLFUFiltersWindowVictims checks that a candidate leaving the window
+// is admitted in place of the main victim when it is more frequent, and is
+// rejected when the main victim is more frequent.
+func TestCsWTinyLFUFiltersWindowVictims(t *testing.T) {
+	setTestCsCapacity(t, 10)
+	cs := &testCsTable{}
+	w := NewCsWTinyLFU(cs, 10.0, 0.8) // window of 1 entry, main region of 9
+
+	for index := range uint64(10) {
+		w.AfterInsert(index, nil, nil)
+	}
+	for range 3 {
+		for index := range uint64(9) {
+			w.BeforeUse(index, nil)
+		}
+	}
+
+	// The window is 9, 0, ..., 8, 10 from least recently used: 9 and 0 to 7 fill the
+	// main region, then the candidate 8 is more frequent than the victim 9.
+	w.AfterInsert(10, nil, nil)
+	w.EvictEntries()
+	if !slices.Equal(cs.erased, []uint64{9}) || w.Stats()["admitted"] != 1 {
+		t.Fatalf("evicted %v with stats %v, want the victim 9 evicted for 8", cs.erased, w.Stats())
+	}
+	if entry := w.entries[8]; entry == nil || entry.region != wtinylfuProbationary {
+		t.Errorf("admitted candidate is not in the probationary segment")
+	}
+
+	// The candidate 10 was seen once, the victim 0 four times.
+	w.AfterInsert(11, nil, nil)
+	w.EvictEntries()
+	if !slices.Equal(cs.erased, []uint64{9, 10}) || w.Stats()["rejected"] != 1 {
+		t.Errorf("evicted %v with stats %v, want the candidate 10 rejected", cs.erased, w.Stats())
+	}
+	if entry := w.entries[0]; entry == nil || entry.region != wtinylfuProbationary {
+		t.Errorf("victim of a rejected candidate is not resident")
+	}
+}
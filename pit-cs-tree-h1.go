@@ -1,6 +1,7 @@
 package table
 
 import (
+	"math/rand"
 	"sync/atomic"
 	"time"
 
@@ -25,14 +26,14 @@ type PitCsTree struct {
 	pitTokens []*nameTreePitEntry
 
 	nCsEntries    atomic.Int64
+	nCsBytes      atomic.Int64
 	csReplacement CsReplacementPolicy
 	csMap         map[uint64]*nameTreeCsEntry
+	csSlots       []*nameTreeCsEntry // CS entries in no particular order, for sampling
 
 	pitExpiryQueue priority_queue.Queue[*nameTreePitEntry, int64]
 	updateTicker   *time.Ticker
 	onExpiration   OnPitExpiration
-
-	lmd				float64 //untuk lambda
 }
 
 type nameTreePitEntry struct {
@@ -45,6 +46,8 @@ type nameTreePitEntry struct {
 type nameTreeCsEntry struct {
 	baseCsEntry                // compose with BasePitEntry
 	node        *pitCsTreeNode // the tree node associated with this entry
+	slot        int            // position in csSlots
+	policyWord  uint32         // metadata kept by sampled replacement policies
 }
 
 // pitCsTreeNode represents an entry in a PIT-CS tree.
@@ -63,6 +66,29 @@ type pitCsTreeNode struct {
 
 // NewPitCS creates a new combined PIT-CS for a forwarding thread.
 func NewPitCS(onExpiration OnPitExpiration) *PitCsTree {
+	return NewPitCSWithPolicy(onExpiration, func(pitCs PitCsTable) CsReplacementPolicy {
+		// The configuration loader is expected to check the policy and its parameters
+		// with ValidateCsPolicy before storing them with SetCfgCsPolicyParams.
+		// This package does not load the configuration itself, so a policy that was
+		// never validated can still fail here, which is fatal.
+		policy := CfgCsReplacementPolicy()
+		csReplacement, err := NewCsPolicy(policy, pitCs, CfgCsPolicyParams(policy))
+		if err != nil {
+			core.Log.Fatal(nil, "Unable to create CS replacement policy", "policy", policy, "err", err)
+		}
+		return csReplacement
+	})
+}
+
+// NewPitCSWithPolicy creates a new combined PIT-CS whose Content Store uses the
+// replacement policy returned by newPolicy instead of the configured one.
+// It is meant for policies that cannot be created from the configuration,
+// such as CsBelady when replaying a trace:
+//
+//	pitCs := NewPitCSWithPolicy(onExpiration, func(cs PitCsTable) CsReplacementPolicy {
+//		return NewCsBelady(cs, future)
+//	})
+func NewPitCSWithPolicy(onExpiration OnPitExpiration, newPolicy func(PitCsTable) CsReplacementPolicy) *PitCsTree {
 	pitCs := new(PitCsTree)
 	pitCs.root = PitCsPools.PitCsTreeNode.New()
 	pitCs.root.component = enc.Component{} // zero component
@@ -70,29 +96,8 @@ func NewPitCS(onExpiration OnPitExpiration) *PitCsTree {
 	pitCs.pitTokens = make([]*nameTreePitEntry, pitTokenLookupTableSize)
 	pitCs.pitExpiryQueue = priority_queue.New[*nameTreePitEntry, int64]()
 	pitCs.updateTicker = time.NewTicker(expiredPitTickerInterval)
-
-
-	// Konfigurasi atau langsung set manual lambda
-	lmd := 0.5 // atau pakai condition
-	// // Clamp ke range 0.0 - 1.0 
-	if lmd < 0.0 {
-	lmd = 0.0
-	} else if lmd > 1.0 {
-		lmd = 1.0
-	}
-	pitCs.lmd = lmd
-	pitCs.csReplacement = NewCsLRFU(pitCs, pitCs.lmd)
-
-
-	// This value has already been validated from loading the configuration,
-	// so we know it will be one of the following (or else fatal)
-	switch CfgCsReplacementPolicy() {
-	case "lrfu":
-		pitCs.csReplacement = NewCsLRFU(pitCs, lmd) //lrfu
-	default:
-		core.Log.Fatal(nil, "Unknown CS replacement policy", "policy", CfgCsReplacementPolicy())
-	}
 	pitCs.csMap = make(map[uint64]*nameTreeCsEntry)
+	pitCs.csReplacement = newPolicy(pitCs)
 
 	return pitCs
 }
@@ -260,6 +265,20 @@ func (p *PitCsTree) CsSize() int {
 	return int(p.nCsEntries.Load())
 }
 
+// CsBytes returns the total size of the Data wires held in the CS.
+func (p *PitCsTree) CsBytes() int {
+	return int(p.nCsBytes.Load())
+}
+
+// CsPolicyStats returns the variables exposed by the CS replacement policy,
+// or nil if the policy does not expose any.
+func (p *PitCsTree) CsPolicyStats() map[string]float64 {
+	if stats, ok := p.csReplacement.(CsPolicyStats); ok {
+		return stats.Stats()
+	}
+	return nil
+}
+
 // IsCsAdmitting returns whether the CS is admitting content.
 func (p *PitCsTree) IsCsAdmitting() bool {
 	return CfgCsAdmit()
@@ -376,13 +395,18 @@ func (p *PitCsTree) InsertData(data *defn.FwData, wire []byte) {
 
 	if entry, ok := p.csMap[index]; ok {
 		// Replace existing entry
+		p.nCsBytes.Add(int64(len(store) - len(entry.wire)))
 		entry.wire = store
 		entry.staleTime = staleTime
 
 		p.csReplacement.AfterRefresh(index, wire, data)
+
+		// The new wire may be larger, which matters under a byte limit
+		p.csReplacement.EvictEntries()
 	} else {
 		// New entry
 		p.nCsEntries.Add(1)
+		p.nCsBytes.Add(int64(len(store)))
 		node := p.root.fillTreeToPrefixEnc(data.NameV)
 		node.csEntry = &nameTreeCsEntry{
 			node: node,
@@ -394,12 +418,13 @@ func (p *PitCsTree) InsertData(data *defn.FwData, wire []byte) {
 		}
 
 		p.csMap[index] = node.csEntry
+		node.csEntry.slot = len(p.csSlots)
+		p.csSlots = append(p.csSlots, node.csEntry)
 		p.csReplacement.AfterInsert(index, wire, data)
 
 		// Tell replacement strategy to evict entries if needed
 		p.csReplacement.EvictEntries()
 
-
 	}
 }
 
@@ -409,10 +434,34 @@ func (p *PitCsTree) eraseCsDataFromReplacementStrategy(index uint64) {
 	if entry, ok := p.csMap[index]; ok {
 		entry.node.csEntry = nil
 		delete(p.csMap, index)
+		last := p.csSlots[len(p.csSlots)-1]
+		last.slot = entry.slot
+		p.csSlots[entry.slot] = last
+		p.csSlots = p.csSlots[:len(p.csSlots)-1]
 		p.nCsEntries.Add(-1)
+		p.nCsBytes.Add(-int64(len(entry.wire)))
 	}
 }
 
+// sampleCsEntry returns the index and policy word of a CS entry chosen
+// uniformly at random, or false if the CS is empty.
+func (p *PitCsTree) sampleCsEntry(rng *rand.Rand) (uint64, *uint32, bool) {
+	if len(p.csSlots) == 0 {
+		return 0, nil, false
+	}
+	entry := p.csSlots[rng.Intn(len(p.csSlots))]
+	return entry.index, &entry.policyWord, true
+}
+
+// csPolicyWord returns the policy word of the CS entry with the specified index,
+// or nil if there is no such entry.
+func (p *PitCsTree) csPolicyWord(index uint64) *uint32 {
+	if entry, ok := p.csMap[index]; ok {
+		return &entry.policyWord
+	}
+	return nil
+}
+
 // Given a pitCsTreeNode that is the longest prefix match of an interest, look for any
 // CS data rechable from this pitCsTreeNode. This function must be called only after
 // the interest as far as possible with the nodes components in the PitCSTree.
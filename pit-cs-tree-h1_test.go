@@ -0,0 +1,138 @@
+package table
+
+import (
+	"testing"
+
+	"github.com/named-data/ndnd/fw/defn"
+	enc "github.com/named-data/ndnd/std/encoding"
+)
+
+// TestNewPitCSWithPolicyBelady replays a trace through a PitCsTree with CsBelady.
+// When /c is inserted into a full Content Store, Belady evicts it rather than
+// /a or /b, which are referenced again, so the last two references are hits.
+func TestNewPitCSWithPolicyBelady(t *testing.T) {
+	setTestCsCapacity(t, 2)
+
+	var trace []enc.Name
+	var future []uint64
+	for _, s := range []string{"/a", "/b", "/c", "/a", "/b"} {
+		name, err := enc.NameFromStr(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		trace = append(trace, name)
+		future = append(future, name.Hash())
+	}
+
+	pitCs := NewPitCSWithPolicy(nil, func(cs PitCsTable) CsReplacementPolicy {
+		return NewCsBelady(cs, future)
+	})
+	var hits []int
+	for i, name := range trace {
+		if pitCs.FindMatchingDataFromCS(&defn.FwInterest{NameV: name}) != nil {
+			hits = append(hits, i)
+		} else {
+			pitCs.InsertData(&defn.FwData{NameV: name}, []byte{byte(i)})
+		}
+	}
+	if len(hits) != 2 || hits[0] != 3 || hits[1] != 4 {
+		t.Errorf("hits at %v, want [3 4]", hits)
+	}
+	if pitCs.CsSize() != 2 {
+		t.Errorf("CS holds %d entries, want 2", pitCs.CsSize())
+	}
+}
+
+// testPitCsInsert inserts a Data packet with the given name and wire size.
+func testPitCsInsert(t *testing.T, pitCs *PitCsTree, name string, size int) {
+	t.Helper()
+	n, err := enc.NameFromStr(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pitCs.InsertData(&defn.FwData{NameV: n}, make([]byte, size))
+}
+
+// testPitCsHas returns whether the Content Store holds Data with the given name.
+func testPitCsHas(t *testing.T, pitCs *PitCsTree, name string) bool {
+	t.Helper()
+	n, err := enc.NameFromStr(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pitCs.FindMatchingDataFromCS(&defn.FwInterest{NameV: n}) != nil
+}
+
+// TestPitCsTreeCountsBytes checks the byte count of the Content Store after an
+// insert, a refresh with a different size, and an eviction.
+func TestPitCsTreeCountsBytes(t *testing.T) {
+	setTestCsCapacity(t, 2)
+	setTestCsCapacityBytes(t, 0)
+	pitCs := NewPitCSWithPolicy(nil, func(cs PitCsTable) CsReplacementPolicy { return NewCsLRU(cs) })
+
+	steps := []struct {
+		name  string
+		size  int
+		bytes int
+	}{
+		{"/a", 100, 100},
+		{"/b", 200, 300},
+		{"/a", 50, 250}, // refresh with a smaller wire
+		{"/a", 400, 600},
+		{"/c", 10, 410}, // evicts /b
+	}
+	for _, step := range steps {
+		testPitCsInsert(t, pitCs, step.name, step.size)
+		if pitCs.CsBytes() != step.bytes {
+			t.Errorf("after inserting %s of %d bytes, CS holds %d bytes, want %d",
+				step.name, step.size, pitCs.CsBytes(), step.bytes)
+		}
+	}
+	if pitCs.CsSize() != 2 || testPitCsHas(t, pitCs, "/b") {
+		t.Errorf("CS holds %d entries, want /a and /c", pitCs.CsSize())
+	}
+}
+
+// TestPitCsTreeEvictsByBytes checks that policies evict against the byte limit
+// while under the entry limit, and that an entry larger than the byte limit
+// does not stay in the Content Store. The checks of which entries are held are
+// themselves hits, so /j is in T2 of ARC when /big is inserted.
+func TestPitCsTreeEvictsByBytes(t *testing.T) {
+	for _, policy := range []string{"lru", "arc"} {
+		t.Run(policy, func(t *testing.T) {
+			setTestCsCapacity(t, 100)
+			setTestCsCapacityBytes(t, 1000)
+			pitCs := NewPitCSWithPolicy(nil, func(cs PitCsTable) CsReplacementPolicy {
+				p, err := NewCsPolicy(policy, cs, nil)
+				if err != nil {
+					t.Fatal(err)
+				}
+				return p
+			})
+
+			for i := range 10 {
+				testPitCsInsert(t, pitCs, "/"+string(rune('a'+i)), 300)
+				if pitCs.CsBytes() > 1000 {
+					t.Fatalf("CS holds %d bytes after %d inserts", pitCs.CsBytes(), i+1)
+				}
+			}
+			if pitCs.CsSize() != 3 || pitCs.CsBytes() != 900 {
+				t.Errorf("CS holds %d entries of %d bytes, want 3 of 900", pitCs.CsSize(), pitCs.CsBytes())
+			}
+			if testPitCsHas(t, pitCs, "/g") || !testPitCsHas(t, pitCs, "/j") {
+				t.Errorf("byte limit did not evict the oldest entries")
+			}
+
+			// The policy may evict other entries first, but not keep this one.
+			testPitCsInsert(t, pitCs, "/big", 2000)
+			if testPitCsHas(t, pitCs, "/big") || pitCs.CsBytes() != 300*pitCs.CsSize() {
+				t.Errorf("CS holds %d entries of %d bytes after an entry over the byte limit, want /big evicted",
+					pitCs.CsSize(), pitCs.CsBytes())
+			}
+			testPitCsInsert(t, pitCs, "/small", 10)
+			if !testPitCsHas(t, pitCs, "/small") {
+				t.Errorf("CS does not admit entries after an entry over the byte limit")
+			}
+		})
+	}
+}